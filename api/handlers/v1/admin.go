@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"expvar"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMetrics godoc
+// @ID get-metrics
+// @Router /v1/admin/metrics [GET]
+// @Summary get gateway metrics
+// @Description Returns the expvar metrics of the gateway. Under "upstream" every protected grpc service reports
+// @Description its circuit breaker state ("<service>.state"), state transitions, retries and calls rejected while open.
+// @Tags admin
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{} "expvar variables"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) GetMetrics(c *gin.Context) {
+	expvar.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// GetUpstreamBackends godoc
+// @ID get-upstream-backends
+// @Router /v1/admin/upstreams [GET]
+// @Summary get upstream backends
+// @Description Lists the replicas of every upstream in config with their connectivity state.
+// @Description With health checking enabled, replicas failing their grpc.health.v1 check are reported as TRANSIENT_FAILURE.
+// @Tags admin
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.ResponseModel{data=models.GetAllUpstreamBackendResponse} "desc"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) GetUpstreamBackends(c *gin.Context) {
+	backends := h.services.Backends()
+
+	res := models.GetAllUpstreamBackendResponse{
+		BackendList: make([]models.UpstreamBackend, 0, len(backends)),
+		Count:       int32(len(backends)),
+	}
+	for _, backend := range backends {
+		res.BackendList = append(res.BackendList, models.UpstreamBackend{
+			Upstream: backend.Upstream,
+			Target:   backend.Target,
+			Address:  backend.Address,
+			State:    backend.State,
+			Error:    backend.Error,
+		})
+	}
+
+	h.handleSuccessResponse(c, http.StatusOK, "ok", res)
+}
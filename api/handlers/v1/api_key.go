@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/pkg/helper"
+	"book-api-gateway/pkg/logger"
+	"book-api-gateway/storage"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var apiKeyScopeMethods = map[string]bool{
+	"*":                true,
+	http.MethodGet:     true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+}
+
+// CreateApiKey godoc
+// @ID create-api-key
+// @Router /v1/api_keys [POST]
+// @Summary create api key
+// @Description Mint a scoped api key. The key is only returned once.
+// @Description The key acts with the caller's role, so its scopes can never grant more than the caller holds,
+// @Description and it can't be scoped for /v1/api_keys or /v1/admin.
+// @Description Keys are kept in memory unless API_KEY_STORE=file, in which case they survive restarts.
+// @Tags api_key
+// @Accept json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param api_key body models.CreateApiKey true "api_key"
+// @Success 201 {object} models.ResponseModel{data=models.CreateApiKeyResponse} "desc"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) CreateApiKey(c *gin.Context) {
+	var req models.CreateApiKey
+	if err := c.BindJSON(&req); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for api key", err.Error())
+		return
+	}
+
+	if err := validateCreateApiKey(req); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for api key", err.Error())
+		return
+	}
+
+	id, secret, err := generateApiKey()
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while generating api key", err.Error())
+		return
+	}
+
+	hash, err := helper.GeneratePasswordHash(secret)
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while hashing api key", err.Error())
+		return
+	}
+
+	key := &storage.ApiKey{
+		Id:         id,
+		Name:       req.Name,
+		SecretHash: string(hash),
+		Role:       c.GetString(CtxUserType),
+		CreatedBy:  c.GetString(CtxUserID),
+		ExpiresAt:  req.ExpiresAt,
+		CreatedAt:  time.Now().UTC(),
+	}
+	for _, scope := range req.Scopes {
+		key.Scopes = append(key.Scopes, storage.ApiKeyScope{
+			Method: strings.ToUpper(scope.Method),
+			Path:   scope.Path,
+		})
+	}
+
+	if err := h.storage.ApiKey().Create(c.Request.Context(), key); err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while creating api key", err.Error())
+		return
+	}
+
+	h.log.Info("api key created", logger.String("api_key_id", id), logger.String("created_by", c.GetString(CtxUserID)))
+	h.handleSuccessResponse(c, http.StatusCreated, "created", models.CreateApiKeyResponse{
+		ApiKey: apiKeyModel(key),
+		Key:    id + "." + secret,
+	})
+}
+
+// GetAllApiKeys godoc
+// @ID get-all-api-keys
+// @Router /v1/api_keys [GET]
+// @Summary get all api keys
+// @Description Get the api keys the caller created, or every key for a superadmin.
+// @Tags api_key
+// @Accept json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.ResponseModel{data=models.GetAllApiKeyResponse} "desc"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) GetAllApiKeys(c *gin.Context) {
+	keys, err := h.storage.ApiKey().GetAll(c.Request.Context())
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while getting api keys", err.Error())
+		return
+	}
+
+	resp := models.GetAllApiKeyResponse{
+		ApiKeyList: make([]models.ApiKey, 0, len(keys)),
+	}
+	for _, key := range keys {
+		if apiKeyVisible(c, key) {
+			resp.ApiKeyList = append(resp.ApiKeyList, apiKeyModel(key))
+		}
+	}
+	resp.Count = int32(len(resp.ApiKeyList))
+
+	h.handleSuccessResponse(c, http.StatusOK, "ok", resp)
+}
+
+// GetApiKey godoc
+// @ID get-api-key
+// @Router /v1/api_keys/{api_key_id} [GET]
+// @Summary get api key by id
+// @Description Get an api key the caller created, or any key for a superadmin.
+// @Tags api_key
+// @Accept json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param api_key_id path string true "api_key_id"
+// @Success 200 {object} models.ResponseModel{data=models.ApiKey} "desc"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 404 {object} models.ResponseModel{error=models.ErrorModel} "Not Found"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) GetApiKey(c *gin.Context) {
+	key, ok := h.getApiKey(c)
+	if !ok {
+		return
+	}
+
+	h.handleSuccessResponse(c, http.StatusOK, "ok", apiKeyModel(key))
+}
+
+// DeleteApiKey godoc
+// @ID delete-api-key
+// @Router /v1/api_keys/{api_key_id} [DELETE]
+// @Summary revoke api key
+// @Description Revoke an api key the caller created, or any key for a superadmin.
+// @Tags api_key
+// @Accept json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param api_key_id path string true "api_key_id"
+// @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 404 {object} models.ResponseModel{error=models.ErrorModel} "Not Found"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) DeleteApiKey(c *gin.Context) {
+	key, ok := h.getApiKey(c)
+	if !ok {
+		return
+	}
+
+	id := key.Id
+	err := h.storage.ApiKey().Delete(c.Request.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		h.handleErrorResponse(c, http.StatusNotFound, ErrNotFound, "api key not found")
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while revoking api key", err.Error())
+		return
+	}
+
+	h.log.Info("api key revoked", logger.String("api_key_id", id), logger.String("revoked_by", c.GetString(CtxUserID)))
+	h.handleSuccessResponse(c, http.StatusOK, "revoked", models.MsgModel{Msg: "Revoked"})
+}
+
+// getApiKey loads the api_key_id key, answering 404 when it does not exist or the caller may not see it
+func (h *handler) getApiKey(c *gin.Context) (*storage.ApiKey, bool) {
+	key, err := h.storage.ApiKey().GetById(c.Request.Context(), c.Param("api_key_id"))
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while getting api key", err.Error())
+		return nil, false
+	}
+
+	if errors.Is(err, storage.ErrNotFound) || !apiKeyVisible(c, key) {
+		h.handleErrorResponse(c, http.StatusNotFound, ErrNotFound, "api key not found")
+		return nil, false
+	}
+
+	return key, true
+}
+
+// apiKeyVisible reports whether the caller created key or is a superadmin user, other keys are hidden as not found
+func apiKeyVisible(c *gin.Context, key *storage.ApiKey) bool {
+	return key.CreatedBy == c.GetString(CtxUserID) || isSuperAdminUser(c)
+}
+
+func validateCreateApiKey(req models.CreateApiKey) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name cannot be blank")
+	}
+	if len(req.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !apiKeyScopeMethods[strings.ToUpper(scope.Method)] {
+			return errors.New("scope method must be an http method or *")
+		}
+		if !strings.HasPrefix(scope.Path, "/") {
+			return errors.New("scope path must start with /")
+		}
+		if apiKeyForbiddenPath(strings.TrimSuffix(scope.Path, "*")) || apiKeyForbiddenPath(scope.Path) {
+			return errors.New("api keys can't be scoped for " + strings.Join(apiKeyForbiddenPaths, " or "))
+		}
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+
+	return nil
+}
+
+// generateApiKey returns a random public key id and secret; clients send them as "<id>.<secret>"
+func generateApiKey() (id string, secret string, err error) {
+	b := make([]byte, 40)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(b[:8]), base64.RawURLEncoding.EncodeToString(b[8:]), nil
+}
+
+func apiKeyModel(key *storage.ApiKey) models.ApiKey {
+	m := models.ApiKey{
+		Id:        key.Id,
+		Name:      key.Name,
+		Scopes:    make([]models.ApiKeyScope, 0, len(key.Scopes)),
+		Role:      key.Role,
+		CreatedBy: key.CreatedBy,
+		ExpiresAt: key.ExpiresAt,
+		CreatedAt: key.CreatedAt,
+	}
+	for _, scope := range key.Scopes {
+		m.Scopes = append(m.Scopes, models.ApiKeyScope{Method: scope.Method, Path: scope.Path})
+	}
+
+	return m
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"book-api-gateway/config"
+	"book-api-gateway/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestApiKeysAreScopedToTheirCreator(t *testing.T) {
+	h := newTestHandler(t, config.Config{SecretKey: testSecretKey})
+	for _, key := range []storage.ApiKey{
+		{Id: "by-admin", Role: SystemUserType, CreatedBy: "admin-1", CreatedAt: time.Unix(1700000000, 0)},
+		{Id: "by-superadmin", Role: SuperAdminUserType, CreatedBy: "superadmin-1", CreatedAt: time.Unix(1700000060, 0)},
+	} {
+		key := key
+		if err := h.storage.ApiKey().Create(context.Background(), &key); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(CtxUserID, c.GetHeader("X-Test-User-Id"))
+		c.Set(CtxUserType, c.GetHeader("X-Test-User-Type"))
+	})
+	router.GET("/v1/api_keys", h.GetAllApiKeys)
+	router.GET("/v1/api_keys/:api_key_id", h.GetApiKey)
+	router.DELETE("/v1/api_keys/:api_key_id", h.DeleteApiKey)
+
+	admin := http.Header{"X-Test-User-Id": {"admin-1"}, "X-Test-User-Type": {SystemUserType}}
+	otherAdmin := http.Header{"X-Test-User-Id": {"admin-2"}, "X-Test-User-Type": {SystemUserType}}
+	superadmin := http.Header{"X-Test-User-Id": {"superadmin-2"}, "X-Test-User-Type": {SuperAdminUserType}}
+
+	t.Run("list", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			header http.Header
+			want   []string
+		}{
+			{name: "creator", header: admin, want: []string{"by-admin"}},
+			{name: "another admin", header: otherAdmin, want: nil},
+			{name: "superadmin", header: superadmin, want: []string{"by-admin", "by-superadmin"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/v1/api_keys", nil)
+				req.Header = tt.header
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				var resp struct {
+					Data struct {
+						ApiKeyList []struct {
+							Id string `json:"id"`
+						} `json:"api_key_list"`
+					} `json:"data"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decoding %s: %v", rec.Body, err)
+				}
+
+				var got []string
+				for _, key := range resp.Data.ApiKeyList {
+					got = append(got, key.Id)
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("GetAllApiKeys() ids = %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("GetAllApiKeys() ids = %v, want %v", got, tt.want)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("get and revoke", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			header http.Header
+			want   int
+		}{
+			{name: "creator reads", method: http.MethodGet, path: "/v1/api_keys/by-admin", header: admin, want: http.StatusOK},
+			{name: "admin reads a superadmin key", method: http.MethodGet, path: "/v1/api_keys/by-superadmin", header: admin, want: http.StatusNotFound},
+			{name: "superadmin reads any key", method: http.MethodGet, path: "/v1/api_keys/by-admin", header: superadmin, want: http.StatusOK},
+			{name: "admin revokes a superadmin key", method: http.MethodDelete, path: "/v1/api_keys/by-superadmin", header: admin, want: http.StatusNotFound},
+			{name: "another admin revokes", method: http.MethodDelete, path: "/v1/api_keys/by-admin", header: otherAdmin, want: http.StatusNotFound},
+			{name: "creator revokes", method: http.MethodDelete, path: "/v1/api_keys/by-admin", header: admin, want: http.StatusOK},
+			{name: "revoked key", method: http.MethodGet, path: "/v1/api_keys/by-admin", header: admin, want: http.StatusNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := serve(router, tt.method, tt.path, tt.header); got != tt.want {
+					t.Fatalf("%s %s status = %d, want %d", tt.method, tt.path, got, tt.want)
+				}
+			})
+		}
+
+		if _, err := h.storage.ApiKey().GetById(context.Background(), "by-superadmin"); errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("the superadmin key was revoked by an admin")
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/pkg/helper"
+	"book-api-gateway/pkg/jwt"
+	"book-api-gateway/storage"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidCredentials = errors.New("invalid login or password")
+
+// dummyPasswordHash is compared against when the login is unknown, so the response takes as long as a wrong
+// password and its timing does not tell which accounts exist. It is the hash of a random password at the
+// cost of helper.GeneratePasswordHash.
+const dummyPasswordHash = "$2a$10$lrGXLJbvdShHqK1BIdM2hudpYa7umTznrowO1ze1AqzDvk3.YOzBm"
+
+// Login godoc
+// @ID login
+// @Router /v1/auth/login [POST]
+// @Summary login
+// @Description Exchange login and password for an access and refresh token pair
+// @Tags auth
+// @Accept json
+// @Produce json,application/problem+json
+// @Param credentials body models.LoginRequest true "credentials"
+// @Success 200 {object} models.ResponseModel{data=models.TokenResponse} "desc"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for login", err.Error())
+		return
+	}
+
+	if err := helper.ValidateLogin(req.Login); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for login", err.Error())
+		return
+	}
+	if err := helper.ValidatePassword(req.Password); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for login", err.Error())
+		return
+	}
+
+	user, err := h.storage.User().GetByLogin(c.Request.Context(), req.Login)
+	if errors.Is(err, storage.ErrNotFound) {
+		_ = helper.ComparePasswordHash(dummyPasswordHash, req.Password)
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, errInvalidCredentials.Error())
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while getting user", err.Error())
+		return
+	}
+
+	if err := helper.ComparePasswordHash(user.PasswordHash, req.Password); err != nil {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, errInvalidCredentials.Error())
+		return
+	}
+
+	h.issueTokens(c, user)
+}
+
+// RefreshToken godoc
+// @ID refresh-token
+// @Router /v1/auth/refresh [POST]
+// @Summary refresh token
+// @Description Exchange a refresh token for a new token pair, revoking the old refresh token
+// @Tags auth
+// @Accept json
+// @Produce json,application/problem+json
+// @Param token body models.RefreshTokenRequest true "refresh token"
+// @Success 200 {object} models.ResponseModel{data=models.TokenResponse} "desc"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) RefreshToken(c *gin.Context) {
+	claims, ok := h.bindRefreshToken(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.storage.User().GetById(c.Request.Context(), claims.UserID)
+	if errors.Is(err, storage.ErrNotFound) {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, "user no longer exists")
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while getting user", err.Error())
+		return
+	}
+
+	if !h.revokeRefreshToken(c, claims) {
+		return
+	}
+
+	h.issueTokens(c, user)
+}
+
+// Logout godoc
+// @ID logout
+// @Router /v1/auth/logout [POST]
+// @Summary logout
+// @Description Revoke a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json,application/problem+json
+// @Param token body models.RefreshTokenRequest true "refresh token"
+// @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) Logout(c *gin.Context) {
+	claims, ok := h.bindRefreshToken(c)
+	if !ok {
+		return
+	}
+
+	if !h.revokeRefreshToken(c, claims) {
+		return
+	}
+
+	h.handleSuccessResponse(c, http.StatusOK, "logged out", models.MsgModel{Msg: "Logged out"})
+}
+
+// bindRefreshToken reads a refresh token from the body and checks that it is valid and not revoked
+func (h *handler) bindRefreshToken(c *gin.Context) (*jwt.Claims, bool) {
+	var req models.RefreshTokenRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for refresh token", err.Error())
+		return nil, false
+	}
+
+	claims, err := jwt.ExtractClaims(req.RefreshToken, []byte(h.cfg.SecretKey))
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, err.Error())
+		return nil, false
+	}
+	if claims.TokenType != jwt.RefreshToken || claims.ID == "" {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, jwt.ErrInvalidToken.Error())
+		return nil, false
+	}
+
+	revoked, err := h.storage.RevokedToken().IsRevoked(c.Request.Context(), claims.ID)
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while checking refresh token", err.Error())
+		return nil, false
+	}
+	if revoked {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, "refresh token is revoked")
+		return nil, false
+	}
+
+	return claims, true
+}
+
+// revokeRefreshToken revokes the refresh token of claims. Only the one request that actually revokes it
+// may go on, so a token replayed concurrently is rotated at most once.
+func (h *handler) revokeRefreshToken(c *gin.Context, claims *jwt.Claims) bool {
+	revoked, err := h.storage.RevokedToken().Revoke(c.Request.Context(), claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while revoking refresh token", err.Error())
+		return false
+	}
+	if !revoked {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, "refresh token is revoked")
+		return false
+	}
+
+	return true
+}
+
+// issueTokens responds with a fresh access and refresh token pair for user
+func (h *handler) issueTokens(c *gin.Context, user *storage.User) {
+	if err := helper.ValidateUserType(user.UserType); err != nil {
+		h.handleErrorResponse(c, http.StatusForbidden, ErrForbidden, err.Error())
+		return
+	}
+
+	accessClaims, err := jwt.NewClaims(user.Id, user.UserType, jwt.AccessToken, h.cfg.AccessTokenTTL)
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while generating token", err.Error())
+		return
+	}
+	accessToken, err := jwt.GenerateJWT(accessClaims, []byte(h.cfg.SecretKey))
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while generating token", err.Error())
+		return
+	}
+
+	refreshClaims, err := jwt.NewClaims(user.Id, user.UserType, jwt.RefreshToken, h.cfg.RefreshTokenTTL)
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while generating token", err.Error())
+		return
+	}
+	refreshToken, err := jwt.GenerateJWT(refreshClaims, []byte(h.cfg.SecretKey))
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while generating token", err.Error())
+		return
+	}
+
+	h.handleSuccessResponse(c, http.StatusOK, "ok", models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.cfg.AccessTokenTTL.Seconds()),
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/pkg/logger"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/status"
+)
+
+// bindBatch binds a JSON array body into the slice pointed to by items and enforces cfg.MaxBatchSize
+func (h *handler) bindBatch(c *gin.Context, items interface{}, message string) bool {
+	if err := c.ShouldBindJSON(items); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, message, err)
+		return false
+	}
+
+	n := reflect.ValueOf(items).Elem().Len()
+	if n == 0 {
+		h.handleErrorResponse(c, http.StatusBadRequest, message, "batch is empty")
+		return false
+	}
+	if n > h.cfg.MaxBatchSize {
+		h.handleErrorResponse(c, http.StatusRequestEntityTooLarge, message, fmt.Sprintf("batch of %d items exceeds the max batch size of %d", n, h.cfg.MaxBatchSize))
+		return false
+	}
+
+	return true
+}
+
+// runBatch calls do for each of the n items of a batch on at most cfg.BatchConcurrency workers
+// and returns the per-item results in request order. Every item gets the route's own timeout while
+// the batch as a whole is bounded by cfg.BatchTimeout; items not started by then fail with 504.
+func (h *handler) runBatch(c *gin.Context, n int, do func(ctx context.Context, i int) models.BatchItemResult) []models.BatchItemResult {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.cfg.BatchTimeout)
+	defer cancel()
+
+	itemTimeout := h.routeTimeout(c)
+	results := make([]models.BatchItemResult, n)
+	indexes := make(chan int)
+
+	workers := h.cfg.BatchConcurrency
+	if workers > n {
+		workers = n
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = h.runBatchItem(ctx, itemTimeout, i, do)
+				results[i].Index = i
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// runBatchItem calls do for item i under its own timeout, unless the batch is already out of time
+func (h *handler) runBatchItem(ctx context.Context, timeout time.Duration, i int, do func(ctx context.Context, i int) models.BatchItemResult) models.BatchItemResult {
+	if err := ctx.Err(); err != nil {
+		code, body := grpcErrorModel(status.FromContextError(err).Err())
+		return models.BatchItemResult{Status: code, Error: &body}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return do(ctx, i)
+}
+
+// batchItemFailed is the result of an item rejected by the upstream service
+func (h *handler) batchItemFailed(id string, err error, message string) models.BatchItemResult {
+	code, body := grpcErrorModel(err)
+	h.log.Error(message, logger.String("id", id), logger.String("grpc_code", status.Code(err).String()), logger.Error(err))
+
+	return models.BatchItemResult{Id: id, Status: code, Error: &body}
+}
+
+// batchItemInvalid is the result of an item that failed validation and never reached the upstream service
+func batchItemInvalid(id string, err error) models.BatchItemResult {
+	body, ok := validationErrorModel(err)
+	if !ok {
+		body = models.ErrorModel{Code: ErrBadRequest, Message: err.Error()}
+		return models.BatchItemResult{Id: id, Status: http.StatusBadRequest, Error: &body}
+	}
+
+	return models.BatchItemResult{Id: id, Status: http.StatusUnprocessableEntity, Error: &body}
+}
+
+// handleBatchResponse answers 200 when every item succeeded and 207 Multi-Status otherwise
+func (h *handler) handleBatchResponse(c *gin.Context, results []models.BatchItemResult) {
+	resp := models.BatchResponse{Results: results}
+	for _, result := range results {
+		if result.Error == nil {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	if resp.Failed > 0 {
+		h.handleSuccessResponse(c, http.StatusMultiStatus, "partially failed", resp)
+		return
+	}
+	h.handleSuccessResponse(c, http.StatusOK, "ok", resp)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/config"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batchRouter serves POST /v1/batch, which runs do for every item of a JSON array of strings
+func batchRouter(h *handler, do func(ctx context.Context, item string) models.BatchItemResult) *gin.Engine {
+	router := gin.New()
+	router.POST("/v1/batch", func(c *gin.Context) {
+		var items []string
+		if !h.bindBatch(c, &items, "wrong input") {
+			return
+		}
+
+		h.handleBatchResponse(c, h.runBatch(c, len(items), func(ctx context.Context, i int) models.BatchItemResult {
+			return do(ctx, items[i])
+		}))
+	})
+
+	return router
+}
+
+func TestRunBatch(t *testing.T) {
+	h := newTestHandler(t, config.Config{MaxBatchSize: 3, BatchConcurrency: 2, BatchTimeout: time.Second, UpstreamTimeout: time.Second})
+	router := batchRouter(h, func(ctx context.Context, item string) models.BatchItemResult {
+		if item == "bad" {
+			return models.BatchItemResult{Id: item, Status: http.StatusBadRequest, Error: &models.ErrorModel{Code: ErrBadRequest}}
+		}
+		return models.BatchItemResult{Id: item, Status: http.StatusCreated}
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		want       int
+		wantIds    []string
+		wantFailed int
+	}{
+		{name: "every item succeeds", body: `["a","b","c"]`, want: http.StatusOK, wantIds: []string{"a", "b", "c"}},
+		{name: "an item fails", body: `["a","bad","c"]`, want: http.StatusMultiStatus, wantIds: []string{"a", "bad", "c"}, wantFailed: 1},
+		{name: "empty batch", body: `[]`, want: http.StatusBadRequest},
+		{name: "batch over the max size", body: `["a","b","c","d"]`, want: http.StatusRequestEntityTooLarge},
+		{name: "not an array", body: `{"a":1}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.wantIds == nil {
+				return
+			}
+
+			var resp struct {
+				Data models.BatchResponse `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding %s: %v", rec.Body, err)
+			}
+			if len(resp.Data.Results) != len(tt.wantIds) {
+				t.Fatalf("results = %+v, want ids %v", resp.Data.Results, tt.wantIds)
+			}
+			for i, result := range resp.Data.Results {
+				if result.Index != i || result.Id != tt.wantIds[i] {
+					t.Fatalf("result %d = %+v, want index %d and id %q", i, result, i, tt.wantIds[i])
+				}
+			}
+			if resp.Data.Failed != tt.wantFailed || resp.Data.Succeeded != len(tt.wantIds)-tt.wantFailed {
+				t.Fatalf("succeeded, failed = %d, %d, want %d, %d", resp.Data.Succeeded, resp.Data.Failed, len(tt.wantIds)-tt.wantFailed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+// testBatchContext returns a gin.Context for a POST /v1/batch request, as runBatch is called with
+func testBatchContext() *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/batch", nil)
+	return c
+}
+
+func TestRunBatchBoundsConcurrency(t *testing.T) {
+	h := newTestHandler(t, config.Config{BatchConcurrency: 3, BatchTimeout: time.Second, UpstreamTimeout: time.Second})
+
+	var (
+		mu           sync.Mutex
+		active, peak int
+	)
+	h.runBatch(testBatchContext(), 20, func(ctx context.Context, i int) models.BatchItemResult {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return models.BatchItemResult{Status: http.StatusOK}
+	})
+
+	if peak > 3 {
+		t.Fatalf("%d items ran at once, want at most BatchConcurrency = 3", peak)
+	}
+}
+
+func TestRunBatchTimeouts(t *testing.T) {
+	t.Run("items get the route timeout", func(t *testing.T) {
+		h := newTestHandler(t, config.Config{BatchConcurrency: 1, BatchTimeout: time.Second, UpstreamTimeout: time.Second})
+
+		results := h.runBatch(testBatchContext(), 2, func(ctx context.Context, i int) models.BatchItemResult {
+			deadline, ok := ctx.Deadline()
+			if !ok || time.Until(deadline) > time.Second {
+				return models.BatchItemResult{Status: http.StatusInternalServerError}
+			}
+			return models.BatchItemResult{Status: http.StatusOK}
+		})
+
+		for i, result := range results {
+			if result.Status != http.StatusOK {
+				t.Fatalf("item %d ran without a deadline within the upstream timeout", i)
+			}
+		}
+	})
+
+	t.Run("items not started within the batch timeout fail with 504", func(t *testing.T) {
+		h := newTestHandler(t, config.Config{BatchConcurrency: 1, BatchTimeout: 20 * time.Millisecond, UpstreamTimeout: time.Minute})
+
+		start := time.Now()
+		results := h.runBatch(testBatchContext(), 3, func(ctx context.Context, i int) models.BatchItemResult {
+			<-ctx.Done()
+			return models.BatchItemResult{Status: http.StatusGatewayTimeout, Error: &models.ErrorModel{}}
+		})
+
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("runBatch() took %v, want it bounded by the batch timeout", elapsed)
+		}
+		for i, result := range results {
+			if result.Status != http.StatusGatewayTimeout || result.Error == nil || result.Index != i {
+				t.Fatalf("result %d = %+v, want a 504 with an error", i, result)
+			}
+		}
+	})
+}
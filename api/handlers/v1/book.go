@@ -3,10 +3,9 @@ package handlers
 import (
 	"book-api-gateway/api/models"
 	"book-api-gateway/genproto/book_service"
-	"book-api-gateway/pkg/util"
-	"context"
-	"errors"
+	"book-api-gateway/pkg/cursor"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,27 +17,35 @@ import (
 // @Description Create Book
 // @Tags book
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param book body models.CreateBook true "book"
 // @Success 200 {object} models.ResponseModel{data=string} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 422 {object} models.ResponseModel{error=models.ErrorModel} "Unprocessable Entity"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) CreateBook(c *gin.Context) {
 	var book models.CreateBook
-	if err := c.BindJSON(&book); err != nil {
-		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for book", err)
+	if !h.bindAndValidate(c, &book, "wrong input for book") {
 		return
 	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	resp, err := h.services.BookService().Create(
-		context.Background(),
+		ctx,
 		&book_service.CreateBook{
 			Name:       book.Name,
 			CategoryId: book.Category_id,
 		},
 	)
 
-	if !handleError(h.log, c, err, "error while creating book") {
+	if !h.handleError(c, err, "error while creating book") {
 		return
 	}
 	h.handleSuccessResponse(c, http.StatusOK, "created", resp)
@@ -51,37 +58,93 @@ func (h *handler) CreateBook(c *gin.Context) {
 // @Description Get All Book
 // @Tags book
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param name query string false "name"
-// @Param limit query string false "limit"
+// @Param sort query string false "sort order, prefix with - for descending" Enums(name, -name, created_at, -created_at, updated_at, -updated_at)
+// @Param category_id query string false "category_id" Format(uuid)
+// @Param ids query []string false "book ids, up to 100" collectionFormat(multi)
+// @Param created_from query string false "created at or after, RFC 3339" Format(date-time)
+// @Param created_to query string false "created at or before, RFC 3339" Format(date-time)
+// @Param updated_from query string false "updated at or after, RFC 3339" Format(date-time)
+// @Param updated_to query string false "updated at or before, RFC 3339" Format(date-time)
+// @Param expand query string false "relations to embed in every book" Enums(category)
+// @Param limit query string false "page size, up to the configured max page size"
 // @Param offset query string false "offset"
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page, replaces limit and offset"
 // @Success 200 {object} models.ResponseModel{data=models.GetAllBookResponse} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Header 200 {string} Link "RFC 8288 links to the next and prev pages"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 422 {object} models.ResponseModel{error=models.ErrorModel} "Validation Failed"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) GetAllBook(c *gin.Context) {
-	limit, err := h.ParseQueryParam(c, "limit", "100")
-	if err != nil {
+	var query models.GetAllBookQuery
+	if !h.bindQueryAndValidate(c, &query, "wrong input query") {
+		return
+	}
+
+	expand, ok := h.parseExpand(c, ExpandCategory)
+	if !ok {
 		return
 	}
 
-	offset, err := h.ParseQueryParam(c, "offset", "0")
-	if err != nil {
+	p, ok := h.parsePage(c, cursor.Fingerprint(
+		query.Name, query.Sort, query.CategoryId, strings.Join(query.Ids, ","),
+		query.CreatedFrom, query.CreatedTo, query.UpdatedFrom, query.UpdatedTo,
+	))
+	if !ok {
 		return
 	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	resp, err := h.services.BookService().GetAll(
-		context.Background(),
+		ctx,
 		&book_service.GetAllBookRequest{
-			Limit:  int32(limit),
-			Offset: int32(offset),
-			Name:   c.Query("name"),
+			Limit:       int32(p.limit),
+			Offset:      int32(p.offset),
+			Name:        query.Name,
+			Sort:        query.Sort,
+			CategoryId:  query.CategoryId,
+			Ids:         query.Ids,
+			CreatedFrom: query.CreatedFrom,
+			CreatedTo:   query.CreatedTo,
+			UpdatedFrom: query.UpdatedFrom,
+			UpdatedTo:   query.UpdatedTo,
 		},
 	)
-	if !handleError(h.log, c, err, "error  getting all attributes") {
+	if !h.handleError(c, err, "error  getting all attributes") {
 		return
 	}
 
-	h.handleSuccessResponse(c, 200, "ok", resp)
+	books := make([]models.Book, 0, len(resp.GetBookList()))
+	for _, book := range resp.GetBookList() {
+		books = append(books, bookModel(book))
+	}
+
+	if expand[ExpandCategory] {
+		categories, err := h.getBookCategories(ctx, books)
+		if !h.handleError(c, err, "error while getting book categories") {
+			return
+		}
+
+		for i := range books {
+			books[i].Category = categories[books[i].Category_id]
+		}
+	}
+
+	next, prev := h.paginate(c, p, int(resp.GetCount()))
+
+	h.handleSuccessResponse(c, 200, "ok", models.GetAllBookResponse{
+		BookList:   books,
+		Count:      resp.GetCount(),
+		NextCursor: next,
+		PrevCursor: prev,
+	})
 }
 
 // GetBook godoc
@@ -91,35 +154,65 @@ func (h *handler) GetAllBook(c *gin.Context) {
 // @Description Get Book By Id
 // @Tags book
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param book_id path string true "book_id"
-// @Success 200 {object} models.ResponseModel{data=models.GetBookResponse} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Param expand query string false "relations to embed" Enums(category)
+// @Success 200 {object} models.ResponseModel{data=models.Book} "desc"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) GetBook(c *gin.Context) {
-	var bookData models.GetBookResponse
-	id := c.Param("book_id")
-	if !util.IsValidUUID(id) {
-		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input  book id", errors.New("wrong input  book id"))
+	id, ok := h.bindUUIDParam(c, "book_id")
+	if !ok {
 		return
 	}
 
+	expand, ok := h.parseExpand(c, ExpandCategory)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	resp, err := h.services.BookService().GetById(
-		context.Background(),
+		ctx,
 		&book_service.BookId{
-			Id: id,
+			Id: id.String(),
 		},
 	)
 
-	if !handleError(h.log, c, err, "error while getting book") {
+	if !h.handleError(c, err, "error while getting book") {
 		return
 	}
-	err = ParseToStruct(&bookData, resp)
-	if err != nil {
-		h.handleErrorResponse(c, http.StatusInternalServerError, "error parsing bookdata", err)
+
+	book := models.Book{
+		Id:          resp.GetId(),
+		Name:        resp.GetName(),
+		Category_id: resp.GetCategoryId(),
+		Created_at:  resp.GetCreatedAt(),
+		Updated_at:  resp.GetUpdatedAt(),
 	}
-	h.handleSuccessResponse(c, http.StatusOK, "ok", bookData)
+
+	if expand[ExpandCategory] && book.Category_id != "" {
+		category, err := h.services.BookCategoryService().GetById(
+			ctx,
+			&book_service.BookCategoryId{
+				Id: book.Category_id,
+			},
+		)
+		if !h.handleError(c, err, "error while getting book category") {
+			return
+		}
+
+		embedded := bookCategoryModel(category)
+		book.Category = &embedded
+	}
+
+	h.handleSuccessResponse(c, http.StatusOK, "ok", book)
 }
 
 // UpdateBook godoc
@@ -129,20 +222,28 @@ func (h *handler) GetBook(c *gin.Context) {
 // @Description Update Book
 // @Tags book
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param book body models.UpdateBook true "book"
 // @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 422 {object} models.ResponseModel{error=models.ErrorModel} "Unprocessable Entity"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) UpdateBook(c *gin.Context) {
 	var updateBook models.UpdateBook
-	if err := c.BindJSON(&updateBook); err != nil {
-		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for update book", err)
+	if !h.bindAndValidate(c, &updateBook, "wrong input for update book") {
 		return
 	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	_, err := h.services.BookService().Update(
-		context.Background(),
+		ctx,
 		&book_service.UpdateBook{
 			Id:         updateBook.Id,
 			CategoryId: updateBook.Category_id,
@@ -150,7 +251,7 @@ func (h *handler) UpdateBook(c *gin.Context) {
 		},
 	)
 
-	if !handleError(h.log, c, err, "error while updating book") {
+	if !h.handleError(c, err, "error while updating book") {
 		return
 	}
 	h.handleSuccessResponse(c, http.StatusOK, "updated", models.MsgModel{Msg: "Updated"})
@@ -163,26 +264,43 @@ func (h *handler) UpdateBook(c *gin.Context) {
 // @Description Delete Book By Id
 // @Tags book
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param book_id path string true "book_id"
 // @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) DeleteBook(c *gin.Context) {
-	id := c.Param("book_id")
-	if !util.IsValidUUID(id) {
-		h.handleErrorResponse(c, http.StatusBadGateway, "wrong input book id", errors.New("wrong input book id"))
+	id, ok := h.bindUUIDParam(c, "book_id")
+	if !ok {
 		return
 	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	_, err := h.services.BookService().Delete(
-		context.Background(),
+		ctx,
 		&book_service.BookId{
-			Id: id,
+			Id: id.String(),
 		},
 	)
-	if !handleError(h.log, c, err, "error while deleting book") {
+	if !h.handleError(c, err, "error while deleting book") {
 		return
 	}
 	h.handleSuccessResponse(c, http.StatusOK, "deleted", models.MsgModel{Msg: "Deleted"})
 }
+
+func bookModel(book *book_service.Book) models.Book {
+	return models.Book{
+		Id:          book.GetId(),
+		Name:        book.GetName(),
+		Category_id: book.GetCategoryId(),
+		Created_at:  book.GetCreatedAt(),
+		Updated_at:  book.GetUpdatedAt(),
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/genproto/book_service"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateBookBatch godoc
+// @ID create-book-batch
+// @Router /v1/book/batch [POST]
+// @Summary create books in batch
+// @Description Create up to the configured max batch size of books. Items are created independently, so the batch may partially succeed.
+// @Tags book
+// @Accept json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param books body []models.CreateBook true "books"
+// @Success 200 {object} models.ResponseModel{data=models.BatchResponse} "every item succeeded"
+// @Success 207 {object} models.ResponseModel{data=models.BatchResponse} "some items failed"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 413 {object} models.ResponseModel{error=models.ErrorModel} "Batch Too Large"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) CreateBookBatch(c *gin.Context) {
+	var books []models.CreateBook
+	if !h.bindBatch(c, &books, "wrong input for create books") {
+		return
+	}
+
+	results := h.runBatch(c, len(books), func(ctx context.Context, i int) models.BatchItemResult {
+		if err := books[i].ValidateWithContext(h.validationContext(ctx)); err != nil {
+			return batchItemInvalid("", err)
+		}
+
+		resp, err := h.services.BookService().Create(
+			ctx,
+			&book_service.CreateBook{
+				Name:       books[i].Name,
+				CategoryId: books[i].Category_id,
+			},
+		)
+		if err != nil {
+			return h.batchItemFailed("", err, "error while creating book")
+		}
+
+		return models.BatchItemResult{Id: resp.GetId(), Status: http.StatusCreated}
+	})
+
+	h.handleBatchResponse(c, results)
+}
+
+// UpdateBookBatch godoc
+// @ID update-book-batch
+// @Router /v1/book/batch [PUT]
+// @Summary update books in batch
+// @Description Update up to the configured max batch size of books. Items are updated independently, so the batch may partially succeed.
+// @Tags book
+// @Accept json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param books body []models.UpdateBook true "books"
+// @Success 200 {object} models.ResponseModel{data=models.BatchResponse} "every item succeeded"
+// @Success 207 {object} models.ResponseModel{data=models.BatchResponse} "some items failed"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 413 {object} models.ResponseModel{error=models.ErrorModel} "Batch Too Large"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) UpdateBookBatch(c *gin.Context) {
+	var books []models.UpdateBook
+	if !h.bindBatch(c, &books, "wrong input for update books") {
+		return
+	}
+
+	results := h.runBatch(c, len(books), func(ctx context.Context, i int) models.BatchItemResult {
+		if err := books[i].ValidateWithContext(h.validationContext(ctx)); err != nil {
+			return batchItemInvalid(books[i].Id, err)
+		}
+
+		_, err := h.services.BookService().Update(
+			ctx,
+			&book_service.UpdateBook{
+				Id:         books[i].Id,
+				CategoryId: books[i].Category_id,
+				Name:       books[i].Name,
+			},
+		)
+		if err != nil {
+			return h.batchItemFailed(books[i].Id, err, "error while updating book")
+		}
+
+		return models.BatchItemResult{Id: books[i].Id, Status: http.StatusOK}
+	})
+
+	h.handleBatchResponse(c, results)
+}
+
+// DeleteBookBatch godoc
+// @ID delete-book-batch
+// @Router /v1/book/batch [DELETE]
+// @Summary delete books in batch
+// @Description Delete up to the configured max batch size of books by id. Items are deleted independently, so the batch may partially succeed.
+// @Tags book
+// @Accept json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param ids body []string true "book ids"
+// @Success 200 {object} models.ResponseModel{data=models.BatchResponse} "every item succeeded"
+// @Success 207 {object} models.ResponseModel{data=models.BatchResponse} "some items failed"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 413 {object} models.ResponseModel{error=models.ErrorModel} "Batch Too Large"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) DeleteBookBatch(c *gin.Context) {
+	var ids []string
+	if !h.bindBatch(c, &ids, "wrong input for delete books") {
+		return
+	}
+
+	results := h.runBatch(c, len(ids), func(ctx context.Context, i int) models.BatchItemResult {
+		id, err := models.ParseUUID(h.validationContext(ctx), ids[i])
+		if err != nil {
+			return batchItemInvalid(ids[i], err)
+		}
+
+		_, err = h.services.BookService().Delete(
+			ctx,
+			&book_service.BookId{
+				Id: id.String(),
+			},
+		)
+		if err != nil {
+			return h.batchItemFailed(id.String(), err, "error while deleting book")
+		}
+
+		return models.BatchItemResult{Id: id.String(), Status: http.StatusOK}
+	})
+
+	h.handleBatchResponse(c, results)
+}
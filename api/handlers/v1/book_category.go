@@ -3,9 +3,7 @@ package handlers
 import (
 	"book-api-gateway/api/models"
 	"book-api-gateway/genproto/book_service"
-	"book-api-gateway/pkg/util"
-	"context"
-	"errors"
+	"book-api-gateway/pkg/cursor"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -18,28 +16,35 @@ import (
 // @Description Create Book Category
 // @Tags book_category
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param book_category body models.CreateBookCategory true "book_category"
 // @Success 200 {object} models.ResponseModel{data=string} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 422 {object} models.ResponseModel{error=models.ErrorModel} "Unprocessable Entity"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) CreateBookCategory(c *gin.Context) {
 	var createBookCategory models.CreateBookCategory
 
-	if err := c.BindJSON(&createBookCategory); err != nil {
-		h.handleErrorResponse(c, 400, "wrong input or type ", err)
+	if !h.bindAndValidate(c, &createBookCategory, "wrong input or type") {
 		return
 	}
 
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	resp, err := h.services.BookCategoryService().Create(
-		context.Background(),
+		ctx,
 		&book_service.CreateBookCategory{
 			Name: createBookCategory.Name,
 		},
 	)
 
-	if !handleError(h.log, c, err, "error while creating book category") {
+	if !h.handleError(c, err, "error while creating book category") {
 		return
 	}
 
@@ -53,39 +58,55 @@ func (h *handler) CreateBookCategory(c *gin.Context) {
 // @Description Get All Book Category
 // @Tags book_category
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param name query string false "name"
-// @Param limit query string false "limit"
+// @Param limit query string false "page size, up to the configured max page size"
 // @Param offset query string false "offset"
+// @Param cursor query string false "next_cursor or prev_cursor of a previous page, replaces limit and offset"
 // @Success 200 {object} models.ResponseModel{data=models.GetAllBookCategoryResponse} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Header 200 {string} Link "RFC 8288 links to the next and prev pages"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) GetAllBookCategory(c *gin.Context) {
-	limit, err := h.ParseQueryParam(c, "limit", "100")
-	if err != nil {
-		return
-	}
+	name := c.Query("name")
 
-	offset, err := h.ParseQueryParam(c, "offset", "0")
-	if err != nil {
+	p, ok := h.parsePage(c, cursor.Fingerprint(name))
+	if !ok {
 		return
 	}
 
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	resp, err := h.services.BookCategoryService().GetAll(
-		context.Background(),
+		ctx,
 		&book_service.GetAllBookCategoryRequest{
-			Limit:  int32(limit),
-			Offset: int32(offset),
-			Name:   c.Query("name"),
+			Limit:  int32(p.limit),
+			Offset: int32(p.offset),
+			Name:   name,
 		},
 	)
-	if !handleError(h.log, c, err, "error  getting all attributes") {
+	if !h.handleError(c, err, "error  getting all attributes") {
 		return
 	}
 
-	h.handleSuccessResponse(c, 200, "ok", resp)
+	categories := make([]models.BookCategory, 0, len(resp.GetBookcategorylist()))
+	for _, category := range resp.GetBookcategorylist() {
+		categories = append(categories, bookCategoryModel(category))
+	}
+
+	next, prev := h.paginate(c, p, int(resp.GetCount()))
 
+	h.handleSuccessResponse(c, 200, "ok", models.GetAllBookCategoryResponse{
+		BookCategoryList: categories,
+		Count:            resp.GetCount(),
+		NextCursor:       next,
+		PrevCursor:       prev,
+	})
 }
 
 // GetBookCategory godoc
@@ -95,32 +116,38 @@ func (h *handler) GetAllBookCategory(c *gin.Context) {
 // @Description Get Book Category By Id
 // @Tags book_category
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param book_category_id path string true "book_category_id"
 // @Success 200 {object} models.ResponseModel{data=models.BookCategory} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) GetBookCategory(c *gin.Context) {
 	var bookCategory models.BookCategory
-	id := c.Param("book_category_id")
-	if !util.IsValidUUID(id) {
-		h.handleErrorResponse(c, http.StatusBadRequest, "wrong uuid of book category", errors.New("wrong uuid of book category"))
+	id, ok := h.bindUUIDParam(c, "book_category_id")
+	if !ok {
 		return
 	}
 
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	resp, err := h.services.BookCategoryService().GetById(
-		context.Background(),
+		ctx,
 		&book_service.BookCategoryId{
-			Id: id,
+			Id: id.String(),
 		},
 	)
-	if !handleError(h.log, c, err, "error getting attribute by id") {
+	if !h.handleError(c, err, "error getting attribute by id") {
 		return
 	}
 	err = ParseToStruct(&bookCategory, resp)
 	if err != nil {
 		h.handleErrorResponse(c, http.StatusInternalServerError, "error parsing book category", err)
+		return
 	}
 	h.handleSuccessResponse(c, http.StatusOK, "ok", bookCategory)
 }
@@ -132,27 +159,34 @@ func (h *handler) GetBookCategory(c *gin.Context) {
 // @Description Update Book Category
 // @Tags book_category
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param book_category body models.UpdateBookCategory true "book_category"
 // @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Response 422 {object} models.ResponseModel{error=models.ErrorModel} "Unprocessable Entity"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) UpdateBookCategory(c *gin.Context) {
 	var bookCategory models.UpdateBookCategory
-	if err := c.BindJSON(&bookCategory); err != nil {
-		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input book category", err)
+	if !h.bindAndValidate(c, &bookCategory, "wrong input book category") {
 		return
 	}
 
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	_, err := h.services.BookCategoryService().Update(
-		context.Background(),
+		ctx,
 		&book_service.BookCategory{
 			Id:   bookCategory.Id,
 			Name: bookCategory.Name,
 		},
 	)
-	if !handleError(h.log, c, err, "error while update book category") {
+	if !h.handleError(c, err, "error while update book category") {
 		return
 	}
 
@@ -167,28 +201,44 @@ func (h *handler) UpdateBookCategory(c *gin.Context) {
 // @Description Delete Book Category By Id
 // @Tags book_category
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
 // @Param book_category_id path string true "book_category_id"
 // @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Response 400 {object} models.ResponseModel{error=string} "Bad Request"
-// @Failure 500 {object} models.ResponseModel{error=string} "Server Error"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
 func (h *handler) DeleteBookCategory(c *gin.Context) {
-	id := c.Param("book_category_id")
-	if !util.IsValidUUID(id) {
-		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input uuid", errors.New("wrong input uuid"))
+	id, ok := h.bindUUIDParam(c, "book_category_id")
+	if !ok {
 		return
 	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
 	_, err := h.services.BookCategoryService().Delete(
-		context.Background(),
+		ctx,
 		&book_service.BookCategoryId{
-			Id: id,
+			Id: id.String(),
 		},
 	)
-	if !handleError(h.log, c, err, "error while deleting book category") {
+	if !h.handleError(c, err, "error while deleting book category") {
 		return
 	}
 
 	h.handleSuccessResponse(c, http.StatusOK, "deleted", models.MsgModel{Msg: "Deleted"})
 
 }
+
+func bookCategoryModel(category *book_service.BookCategory) models.BookCategory {
+	return models.BookCategory{
+		Id:         category.GetId(),
+		Name:       category.GetName(),
+		Created_at: category.GetCreatedAt(),
+		Updated_at: category.GetUpdatedAt(),
+	}
+}
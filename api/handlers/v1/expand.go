@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/genproto/book_service"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExpandCategory embeds the book category into book responses
+const ExpandCategory = "category"
+
+// parseExpand reads the comma separated "expand" query param and rejects relations not in allowed
+func (h *handler) parseExpand(c *gin.Context, allowed ...string) (map[string]bool, bool) {
+	expand := make(map[string]bool)
+
+	for _, value := range c.QueryArray("expand") {
+		for _, relation := range strings.Split(value, ",") {
+			relation = strings.TrimSpace(relation)
+			if relation == "" {
+				continue
+			}
+			if !contains(allowed, relation) {
+				h.handleErrorResponse(c, http.StatusBadRequest, "wrong input expand", "cannot expand "+relation+", allowed: "+strings.Join(allowed, ","))
+				return nil, false
+			}
+			expand[relation] = true
+		}
+	}
+
+	return expand, true
+}
+
+// getBookCategories fetches the distinct categories referenced by books, with at most
+// cfg.ExpandConcurrency calls in flight. Categories the book service no longer knows are left out.
+func (h *handler) getBookCategories(ctx context.Context, books []models.Book) (map[string]*models.BookCategory, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		firstErr   error
+		categories = make(map[string]*models.BookCategory)
+		sem        = make(chan struct{}, h.cfg.ExpandConcurrency)
+	)
+
+	ids := make(map[string]bool)
+	for _, book := range books {
+		if book.Category_id != "" {
+			ids[book.Category_id] = true
+		}
+	}
+
+	for id := range ids {
+		id := id
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			category, err := h.services.BookCategoryService().GetById(ctx, &book_service.BookCategoryId{Id: id})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case status.Code(err) == codes.NotFound:
+			case err != nil:
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+			default:
+				model := bookCategoryModel(category)
+				categories[id] = &model
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return categories, firstErr
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
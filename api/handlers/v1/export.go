@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/genproto/book_service"
+	"book-api-gateway/pkg/logger"
+	"book-api-gateway/pkg/tabular"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportErrorTrailer is the HTTP trailer reporting why an export stopped after its body had started
+const exportErrorTrailer = "X-Export-Error"
+
+var (
+	bookExportColumns         = []string{"id", "name", "category_id", "created_at", "updated_at"}
+	bookCategoryExportColumns = []string{"id", "name", "created_at", "updated_at"}
+)
+
+// exportPage fetches the rows of an export at offset, along with the current total count
+type exportPage func(ctx context.Context, limit, offset int) (rows [][]string, count int, err error)
+
+// ExportBook godoc
+// @ID export-book
+// @Router /v1/book/export [GET]
+// @Summary export books
+// @Description Stream every book matching the filters of GET /v1/book as CSV, JSON Lines or XLSX.
+// @Description Failures after the body has started are reported in the X-Export-Error trailer.
+// @Description CSV and JSON Lines are streamed page by page, while XLSX is built in full before any of it is sent.
+// @Description Exports of any size are streamed, over HTTP/1 HTTP_WRITE_TIMEOUT bounds each page rather than the whole body. Send async=true to run the export as a background job instead.
+// @Tags book
+// @Produce text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,json,application/problem+json
+// @Security ApiKeyAuth
+// @Param format query string false "file format" Enums(csv, jsonl, xlsx) default(csv)
+// @Param name query string false "name"
+// @Param sort query string false "sort order, prefix with - for descending" Enums(name, -name, created_at, -created_at, updated_at, -updated_at)
+// @Param category_id query string false "category_id" Format(uuid)
+// @Param ids query []string false "book ids, up to 100" collectionFormat(multi)
+// @Param created_from query string false "created at or after, RFC 3339" Format(date-time)
+// @Param created_to query string false "created at or before, RFC 3339" Format(date-time)
+// @Param updated_from query string false "updated at or after, RFC 3339" Format(date-time)
+// @Param updated_to query string false "updated at or before, RFC 3339" Format(date-time)
+// @Param async query bool false "run as a background job, see /v1/jobs/{job_id}"
+// @Success 200 {file} file "books"
+// @Success 202 {object} models.ResponseModel{data=models.Job} "job submitted"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 422 {object} models.ResponseModel{error=models.ErrorModel} "Validation Failed"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 503 {object} models.ResponseModel{error=models.ErrorModel} "Job Queue Full"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) ExportBook(c *gin.Context) {
+	format, ok := h.parseExportFormat(c)
+	if !ok {
+		return
+	}
+
+	var query models.GetAllBookQuery
+	if !h.bindQueryAndValidate(c, &query, "wrong input query") {
+		return
+	}
+
+	async, ok := h.parseAsync(c)
+	if !ok {
+		return
+	}
+	if async {
+		h.submitExport(c, jobBookExport, "books", format, bookExportColumns, h.bookExportPage(query))
+		return
+	}
+
+	h.export(c, "books", format, bookExportColumns, h.bookExportPage(query))
+}
+
+// ExportBookCategory godoc
+// @ID export-book-category
+// @Router /v1/book_category/export [GET]
+// @Summary export book categories
+// @Description Stream every book category matching the name filter as CSV, JSON Lines or XLSX.
+// @Description Failures after the body has started are reported in the X-Export-Error trailer.
+// @Description CSV and JSON Lines are streamed page by page, while XLSX is built in full before any of it is sent.
+// @Description Exports of any size are streamed, over HTTP/1 HTTP_WRITE_TIMEOUT bounds each page rather than the whole body. Send async=true to run the export as a background job instead.
+// @Tags book_category
+// @Produce text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,json,application/problem+json
+// @Security ApiKeyAuth
+// @Param format query string false "file format" Enums(csv, jsonl, xlsx) default(csv)
+// @Param name query string false "name"
+// @Param async query bool false "run as a background job, see /v1/jobs/{job_id}"
+// @Success 200 {file} file "book categories"
+// @Success 202 {object} models.ResponseModel{data=models.Job} "job submitted"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 503 {object} models.ResponseModel{error=models.ErrorModel} "Job Queue Full"
+// @Failure 504 {object} models.ResponseModel{error=models.ErrorModel} "Gateway Timeout"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) ExportBookCategory(c *gin.Context) {
+	format, ok := h.parseExportFormat(c)
+	if !ok {
+		return
+	}
+
+	async, ok := h.parseAsync(c)
+	if !ok {
+		return
+	}
+	if async {
+		h.submitExport(c, jobBookCategoryExport, "book_categories", format, bookCategoryExportColumns, h.bookCategoryExportPage(c.Query("name")))
+		return
+	}
+
+	h.export(c, "book_categories", format, bookCategoryExportColumns, h.bookCategoryExportPage(c.Query("name")))
+}
+
+func (h *handler) bookExportPage(query models.GetAllBookQuery) exportPage {
+	return func(ctx context.Context, limit, offset int) ([][]string, int, error) {
+		resp, err := h.services.BookService().GetAll(
+			ctx,
+			&book_service.GetAllBookRequest{
+				Limit:       int32(limit),
+				Offset:      int32(offset),
+				Name:        query.Name,
+				Sort:        query.Sort,
+				CategoryId:  query.CategoryId,
+				Ids:         query.Ids,
+				CreatedFrom: query.CreatedFrom,
+				CreatedTo:   query.CreatedTo,
+				UpdatedFrom: query.UpdatedFrom,
+				UpdatedTo:   query.UpdatedTo,
+			},
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		rows := make([][]string, 0, len(resp.GetBookList()))
+		for _, book := range resp.GetBookList() {
+			rows = append(rows, []string{book.GetId(), book.GetName(), book.GetCategoryId(), book.GetCreatedAt(), book.GetUpdatedAt()})
+		}
+
+		return rows, int(resp.GetCount()), nil
+	}
+}
+
+func (h *handler) bookCategoryExportPage(name string) exportPage {
+	return func(ctx context.Context, limit, offset int) ([][]string, int, error) {
+		resp, err := h.services.BookCategoryService().GetAll(
+			ctx,
+			&book_service.GetAllBookCategoryRequest{
+				Limit:  int32(limit),
+				Offset: int32(offset),
+				Name:   name,
+			},
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		rows := make([][]string, 0, len(resp.GetBookcategorylist()))
+		for _, category := range resp.GetBookcategorylist() {
+			rows = append(rows, []string{category.GetId(), category.GetName(), category.GetCreatedAt(), category.GetUpdatedAt()})
+		}
+
+		return rows, int(resp.GetCount()), nil
+	}
+}
+
+func (h *handler) parseExportFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", tabular.CSV)
+	if format != tabular.CSV && format != tabular.JSONL && format != tabular.XLSX {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input format", "format must be csv, jsonl or xlsx")
+		return "", false
+	}
+
+	return format, true
+}
+
+// export streams every page of a listing to the client. The first page is fetched before anything
+// is written so that upstream errors still get a regular error response; later failures can only
+// be reported in the exportErrorTrailer trailer. The write deadline is extended after every page so
+// that exports of any size can be streamed, see extendWriteDeadline.
+func (h *handler) export(c *gin.Context, filename, format string, columns []string, page exportPage) {
+	ctx := c.Request.Context()
+
+	pageCtx, cancel := context.WithTimeout(ctx, h.cfg.UpstreamTimeout)
+	first, count, err := page(pageCtx, h.cfg.MaxPageSize, 0)
+	cancel()
+	if !h.handleError(c, err, "error while exporting") {
+		return
+	}
+
+	c.Header("Content-Type", tabular.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+format))
+	c.Header("Trailer", exportErrorTrailer)
+	c.Status(http.StatusOK)
+	h.extendWriteDeadline(c)
+
+	w, err := tabular.NewWriter(format, c.Writer, columns)
+	if err == nil {
+		err = h.exportAll(ctx, w, page, first, count, func() {
+			c.Writer.Flush()
+			h.extendWriteDeadline(c)
+		})
+	}
+	if err != nil {
+		h.log.Error("error while exporting", logger.String("path", c.FullPath()), logger.Error(err))
+		c.Writer.Header().Set(exportErrorTrailer, err.Error())
+	}
+}
+
+// exportAll writes first, the page at offset 0 of a listing of count rows, and then every following
+// page to w, calling flush after each of them. Each page is bounded by cfg.UpstreamTimeout.
+func (h *handler) exportAll(ctx context.Context, w tabular.Writer, page exportPage, first [][]string, count int, flush func()) error {
+	rows := first
+	limit := h.cfg.MaxPageSize
+
+	for offset := 0; ; {
+		for _, row := range rows {
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		flush()
+
+		offset += limit
+		if len(rows) == 0 || offset >= count {
+			break
+		}
+
+		var err error
+		pageCtx, cancel := context.WithTimeout(ctx, h.cfg.UpstreamTimeout)
+		rows, count, err = page(pageCtx, limit, offset)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// extendWriteDeadline gives the response another cfg.HttpWriteTimeout to be written, so that the
+// timeout bounds each page of an export instead of the whole body. It only reaches HTTP/1 connections,
+// the per-stream deadline of HTTP/2 can not be changed from a handler before Go 1.20.
+func (h *handler) extendWriteDeadline(c *gin.Context) {
+	conn, ok := c.Request.Context().Value(connCtxKey{}).(net.Conn)
+	if !ok || c.Request.ProtoMajor != 1 || h.cfg.HttpWriteTimeout <= 0 {
+		return
+	}
+
+	if err := conn.SetWriteDeadline(time.Now().Add(h.cfg.HttpWriteTimeout)); err != nil {
+		h.log.Debug("error while extending write deadline", logger.Error(err))
+	}
+}
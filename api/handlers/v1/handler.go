@@ -3,96 +3,367 @@ package handlers
 import (
 	"book-api-gateway/api/models"
 	"book-api-gateway/config"
+	"book-api-gateway/pkg/jobs"
 	"book-api-gateway/pkg/logger"
+	"book-api-gateway/pkg/otp"
+	"book-api-gateway/pkg/ratelimit"
+	"book-api-gateway/pkg/rbac"
+	"book-api-gateway/pkg/uuid"
 	"book-api-gateway/services"
+	"book-api-gateway/storage"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/golang/protobuf/jsonpb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/runtime/protoiface"
+
+	// registers google.rpc error detail types so statusDetails can decode them
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
 )
 
 var (
+	ErrBadRequest          = "BAD_REQUEST"
+	ErrCanceled            = "CANCELED"
+	ErrUnknown             = "UNKNOWN"
+	ErrInvalidArgument     = "INVALID_ARGUMENT"
 	ErrAlreadyExists       = "ALREADY_EXISTS"
 	ErrNotFound            = "NOT_FOUND"
+	ErrFailedPrecondition  = "FAILED_PRECONDITION"
+	ErrResourceExhausted   = "RESOURCE_EXHAUSTED"
+	ErrAborted             = "ABORTED"
+	ErrOutOfRange          = "OUT_OF_RANGE"
+	ErrNotImplemented      = "NOT_IMPLEMENTED"
+	ErrDataLoss            = "DATA_LOSS"
 	ErrInternalServerError = "INTERNAL_SERVER_ERROR"
 	ErrServiceUnavailable  = "SERVICE_UNAVAILABLE"
-	SigningKey             = []byte("FfLbN7pIEYe8@!EqrttOLiwa(H8)7Ddo")
+	ErrGatewayTimeout      = "GATEWAY_TIMEOUT"
+	ErrUnauthorized        = "UNAUTHORIZED"
+	ErrForbidden           = "FORBIDDEN"
+	ErrValidationFailed    = "VALIDATION_FAILED"
+	ErrTooManyRequests     = "TOO_MANY_REQUESTS"
 	SuperAdminUserType     = "superadmin"
 	SystemUserType         = "admin"
 )
 
+const problemContentType = "application/problem+json"
+
+// statusClientClosedRequest is the non-standard status used when the client went away before the upstream answered
+const statusClientClosedRequest = 499
+
+// grpcErrors maps every grpc status code to the http status and error code returned to clients
+var grpcErrors = map[codes.Code]struct {
+	status int
+	code   string
+}{
+	codes.Canceled:           {statusClientClosedRequest, ErrCanceled},
+	codes.Unknown:            {http.StatusInternalServerError, ErrUnknown},
+	codes.InvalidArgument:    {http.StatusBadRequest, ErrInvalidArgument},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, ErrGatewayTimeout},
+	codes.NotFound:           {http.StatusNotFound, ErrNotFound},
+	codes.AlreadyExists:      {http.StatusConflict, ErrAlreadyExists},
+	codes.PermissionDenied:   {http.StatusForbidden, ErrForbidden},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, ErrResourceExhausted},
+	codes.FailedPrecondition: {http.StatusBadRequest, ErrFailedPrecondition},
+	codes.Aborted:            {http.StatusConflict, ErrAborted},
+	codes.OutOfRange:         {http.StatusBadRequest, ErrOutOfRange},
+	codes.Unimplemented:      {http.StatusNotImplemented, ErrNotImplemented},
+	codes.Internal:           {http.StatusInternalServerError, ErrInternalServerError},
+	codes.Unavailable:        {http.StatusServiceUnavailable, ErrServiceUnavailable},
+	codes.DataLoss:           {http.StatusInternalServerError, ErrDataLoss},
+	codes.Unauthenticated:    {http.StatusUnauthorized, ErrUnauthorized},
+}
+
 type handler struct {
-	log      logger.Logger
-	cfg      config.Config
-	services services.ServicesI
+	log       logger.Logger
+	cfg       config.Config
+	services  services.ServicesI
+	policy    *rbac.Policy
+	storage   storage.StorageI
+	otpSender otp.Sender
+	jobs      *jobs.Manager
+	limiter   ratelimit.Store
 }
 
 type HandlerOptions struct {
-	Log      logger.Logger
-	Cfg      config.Config
-	Services services.ServicesI
+	Log       logger.Logger
+	Cfg       config.Config
+	Services  services.ServicesI
+	Policy    *rbac.Policy
+	Storage   storage.StorageI
+	OTPSender otp.Sender
+	Jobs      *jobs.Manager
+	Limiter   ratelimit.Store
 }
 
 func NewHandler(options *HandlerOptions) *handler {
 	return &handler{
-		log:      options.Log,
-		cfg:      options.Cfg,
-		services: options.Services,
-	}
-}
-func handleError(log logger.Logger, c *gin.Context, err error, message string) (hasError bool) {
-	st, ok := status.FromError(err)
-	if st.Code() == codes.Canceled {
-		log.Error(message+", canceled ", logger.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   st.Message(),
-		})
-		return
-	} else if st.Code() == codes.AlreadyExists || st.Code() == codes.InvalidArgument {
-		log.Error(message+", already exists", logger.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   ErrAlreadyExists,
-		})
-		return
-	} else if st.Code() == codes.NotFound {
-		log.Error(message+", not found", logger.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   ErrNotFound,
-		})
-		return
-	} else if st.Code() == codes.Unavailable {
-		log.Error(message+", service unavailable", logger.Error(err))
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"success": false,
-			"error":   ErrServiceUnavailable,
-		})
-		return
-	} else if !ok || st.Code() == codes.Internal || st.Code() == codes.Unknown || err != nil {
-		log.Error(message+", internal server error", logger.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   ErrInternalServerError,
-		})
-		return
+		log:       options.Log,
+		cfg:       options.Cfg,
+		services:  options.Services,
+		policy:    options.Policy,
+		storage:   options.Storage,
+		otpSender: options.OTPSender,
+		jobs:      options.Jobs,
+		limiter:   options.Limiter,
+	}
+}
+
+// handleError writes the error response for a failed grpc call and reports whether err was nil
+func (h *handler) handleError(c *gin.Context, err error, message string) (ok bool) {
+	if err == nil {
+		return true
 	}
-	return true
+
+	code, body := grpcErrorModel(err)
+
+	h.log.Error(message, logger.String("grpc_code", status.Code(err).String()), logger.Error(err))
+	h.writeError(c, code, message, body)
+	return false
+}
+
+// grpcErrorModel maps an error returned by an upstream call to its HTTP status and error body
+func grpcErrorModel(err error) (int, models.ErrorModel) {
+	st, isStatus := status.FromError(err)
+	if !isStatus {
+		st = status.New(codes.Internal, "internal server error")
+	}
+
+	mapped, found := grpcErrors[st.Code()]
+	if !found {
+		mapped = grpcErrors[codes.Unknown]
+	}
+
+	details := statusDetails(st)
+
+	return mapped.status, models.ErrorModel{
+		Code:    mapped.code,
+		Message: st.Message(),
+		Fields:  fieldViolations(details),
+		Details: details,
+	}
+}
+
+// statusDetails decodes google.rpc.Status details (BadRequest, RetryInfo, ...) into JSON objects keyed like protojson
+func statusDetails(st *status.Status) []map[string]interface{} {
+	var details []map[string]interface{}
+	for _, detail := range st.Proto().GetDetails() {
+		decoded := map[string]interface{}{}
+
+		js, err := protojson.Marshal(detail)
+		if err != nil || json.Unmarshal(js, &decoded) != nil {
+			// the detail type is not linked into the gateway, so only its type is known
+			decoded = map[string]interface{}{"@type": detail.GetTypeUrl()}
+		}
+
+		details = append(details, decoded)
+	}
+
+	return details
+}
+
+// fieldViolations lifts the field violations of a decoded google.rpc.BadRequest detail into field errors
+func fieldViolations(details []map[string]interface{}) []models.FieldError {
+	var fields []models.FieldError
+	for _, detail := range details {
+		if detail["@type"] != "type.googleapis.com/google.rpc.BadRequest" {
+			continue
+		}
+
+		violations, _ := detail["fieldViolations"].([]interface{})
+		for _, v := range violations {
+			violation, _ := v.(map[string]interface{})
+			fields = append(fields, models.FieldError{
+				Field:   fmt.Sprint(violation["field"]),
+				Message: fmt.Sprint(violation["description"]),
+			})
+		}
+	}
+
+	return fields
+}
+
+// requestContext derives the context for upstream calls from the client request, bounded by the route's timeout
+func (h *handler) requestContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), h.routeTimeout(c))
+}
+
+// routeTimeout is the route's timeout from config.RouteTimeouts or config.UpstreamTimeout
+func (h *handler) routeTimeout(c *gin.Context) time.Duration {
+	if timeout, ok := h.cfg.RouteTimeouts[c.Request.Method+" "+c.FullPath()]; ok {
+		return timeout
+	}
+
+	return h.cfg.UpstreamTimeout
 }
 
 func (h *handler) handleErrorResponse(c *gin.Context, code int, message string, err interface{}) {
 	h.log.Error(message, logger.Int("code", code), logger.Any("error", err))
-	c.JSON(code, models.ResponseModel{
-		Code:    code,
-		Message: message,
-		Error:   err,
-	})
+	h.writeError(c, code, message, newErrorModel(code, err))
+}
+
+// writeError is the single place error responses are rendered, either as a ResponseModel
+// or, when the client asks for it or config.ErrorFormat is "problem", as RFC 7807 problem+json
+func (h *handler) writeError(c *gin.Context, code int, message string, e models.ErrorModel) {
+	if h.cfg.ErrorFormat != "problem" && !strings.Contains(c.GetHeader("Accept"), problemContentType) {
+		c.JSON(code, models.ResponseModel{
+			Code:    code,
+			Message: message,
+			Error:   e,
+		})
+		return
+	}
+
+	detail := e.Message
+	if detail == "" {
+		detail = message
+	}
+
+	problemType := "about:blank"
+	if h.cfg.ProblemTypeBaseURL != "" {
+		problemType = strings.TrimSuffix(h.cfg.ProblemTypeBaseURL, "/") + "/" + strings.ToLower(strings.ReplaceAll(e.Code, "_", "-"))
+	}
+
+	// render.JSON keeps an already set Content-Type
+	c.Header("Content-Type", problemContentType)
+	c.Render(code, render.JSON{Data: models.ProblemDetails{
+		Type:     problemType,
+		Title:    http.StatusText(code),
+		Status:   code,
+		Detail:   detail,
+		Instance: c.Request.URL.RequestURI(),
+		Code:     e.Code,
+		TraceId:  c.GetString(CtxRequestID),
+		Errors:   e.Fields,
+		Details:  e.Details,
+	}})
+}
+
+// newErrorModel wraps err in the error envelope, deriving the error code from the http status
+func newErrorModel(code int, err interface{}) models.ErrorModel {
+	switch e := err.(type) {
+	case models.ErrorModel:
+		return e
+	case error:
+		return models.ErrorModel{Code: httpErrorCode(code), Message: e.Error()}
+	case string:
+		return models.ErrorModel{Code: httpErrorCode(code), Message: e}
+	case nil:
+		return models.ErrorModel{Code: httpErrorCode(code)}
+	default:
+		return models.ErrorModel{Code: httpErrorCode(code), Message: fmt.Sprint(e)}
+	}
+}
+
+// httpErrorCode turns an http status into an error code, e.g. 404 into "NOT_FOUND"
+func httpErrorCode(code int) string {
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(code), " ", "_"))
+}
+
+// bindAndValidate binds the JSON body into obj and runs its validation rules before anything reaches
+// the upstream services. It answers 400 for malformed JSON and 422 with per-field errors for invalid input.
+func (h *handler) bindAndValidate(c *gin.Context, obj interface{}, message string) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, message, err)
+		return false
+	}
+
+	return h.validate(c, obj, message)
+}
+
+// bindQueryAndValidate is bindAndValidate for query string parameters
+func (h *handler) bindQueryAndValidate(c *gin.Context, obj interface{}, message string) bool {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, message, err)
+		return false
+	}
+
+	return h.validate(c, obj, message)
+}
+
+// validate writes a 422 with the per-field errors of obj when it is invalid. obj is a validation.Validatable
+// or, when its rules depend on the configuration, a validation.ValidatableWithContext.
+func (h *handler) validate(c *gin.Context, obj interface{}, message string) bool {
+	err := validation.ValidateWithContext(h.validationContext(c.Request.Context()), obj)
+	if err == nil {
+		return true
+	}
+
+	body, ok := validationErrorModel(err)
+	if !ok {
+		h.handleErrorResponse(c, http.StatusInternalServerError, message, err)
+		return false
+	}
+
+	h.handleErrorResponse(c, http.StatusUnprocessableEntity, message, body)
+	return false
+}
+
+// validationErrorModel turns the ozzo validation errors of err into a VALIDATION_FAILED error body
+func validationErrorModel(err error) (models.ErrorModel, bool) {
+	var fieldErrs validation.Errors
+	if !errors.As(err, &fieldErrs) {
+		return models.ErrorModel{}, false
+	}
+
+	return models.ErrorModel{
+		Code:    ErrValidationFailed,
+		Message: "validation failed",
+		Fields:  validationFieldErrors("", fieldErrs),
+	}, true
+}
+
+// validationFieldErrors flattens nested ozzo validation errors into a field list sorted by field name
+func validationFieldErrors(prefix string, errs validation.Errors) []models.FieldError {
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fields []models.FieldError
+	for _, key := range keys {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+
+		if nested, ok := errs[key].(validation.Errors); ok {
+			fields = append(fields, validationFieldErrors(field, nested)...)
+			continue
+		}
+		fields = append(fields, models.FieldError{Field: field, Message: errs[key].Error()})
+	}
+
+	return fields
+}
+
+// validationContext returns ctx carrying the configuration the validation rules of the models depend on
+func (h *handler) validationContext(ctx context.Context) context.Context {
+	return models.WithUUIDVersions(ctx, h.cfg.UUIDVersions)
+}
+
+// bindUUIDParam parses the path param key with models.ParseUUID, answering 400 otherwise
+func (h *handler) bindUUIDParam(c *gin.Context, key string) (uuid.UUID, bool) {
+	id, err := models.ParseUUID(h.validationContext(c.Request.Context()), c.Param(key))
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input "+key, err)
+		return uuid.Nil, false
+	}
+
+	return id, true
 }
 
 func (h *handler) handleSuccessResponse(c *gin.Context, code int, message string, data interface{}) {
@@ -108,10 +379,7 @@ func (h *handler) ParseQueryParam(c *gin.Context, key string, defaultValue strin
 
 	value, err := strconv.Atoi(valueStr)
 	if err != nil {
-		h.log.Error("error while parsing query param"+", canceled ", logger.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		h.handleErrorResponse(c, http.StatusBadRequest, "error while parsing query param "+key, err)
 		c.Abort()
 		return 0, err
 	}
@@ -120,10 +388,7 @@ func (h *handler) ParseQueryParam(c *gin.Context, key string, defaultValue strin
 }
 
 func (h *handler) BadRequestResponse(c *gin.Context, err error) {
-	c.JSON(http.StatusBadRequest, gin.H{
-		"success": false,
-		"error":   err.Error(),
-	})
+	h.handleErrorResponse(c, http.StatusBadRequest, "bad request", err)
 }
 
 func ParseToStruct(data interface{}, m protoiface.MessageV1) error {
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthLive godoc
+// @ID health-live
+// @Router /health/live [GET]
+// @Summary liveness probe
+// @Description Reports that the gateway process is up
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
+func (h *handler) HealthLive(c *gin.Context) {
+	h.handleSuccessResponse(c, http.StatusOK, "ok", models.MsgModel{Msg: "alive"})
+}
+
+// HealthReady godoc
+// @ID health-ready
+// @Router /health/ready [GET]
+// @Summary readiness probe
+// @Description Reports whether the book service connection is usable and its grpc.health.v1 check is SERVING
+// @Tags health
+// @Produce json,application/problem+json
+// @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
+// @Failure 503 {object} models.ResponseModel{error=models.ErrorModel} "Service Unavailable"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) HealthReady(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.cfg.HealthCheckTimeout)
+	defer cancel()
+
+	if err := h.services.HealthCheck(ctx); err != nil {
+		h.handleErrorResponse(c, http.StatusServiceUnavailable, ErrServiceUnavailable, err.Error())
+		return
+	}
+
+	h.handleSuccessResponse(c, http.StatusOK, "ok", models.MsgModel{Msg: "ready"})
+}
@@ -0,0 +1,515 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/genproto/book_service"
+	"book-api-gateway/pkg/rbac"
+	"book-api-gateway/pkg/tabular"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxImportErrors bounds the row errors kept in an import report
+const maxImportErrors = 100
+
+// errTooManyImportRows stops an import with more rows than importOptions.maxRows
+var errTooManyImportRows = errors.New("too many rows to import without async=true")
+
+type importOptions struct {
+	format           string
+	dryRun           bool
+	createCategories bool
+	// maxRows, when set, stops the import once that many rows were read
+	maxRows int
+	// progress, when set, is told the number of rows read so far
+	progress func(rows int)
+}
+
+// importWrite writes a prepared row to the upstream service
+type importWrite func(ctx context.Context) error
+
+// ImportBook godoc
+// @ID import-book
+// @Router /v1/book/import [POST]
+// @Summary import books
+// @Description Import books from a CSV file with a header row or a JSON Lines file, with name and either category_id or category (a category name) columns.
+// @Description Rows are imported independently as they are read, so the import may partially succeed.
+// @Description Without async=true the import stops after MAX_SYNC_IMPORT_ROWS rows (1000 by default) or BATCH_TIMEOUT, with the reason in the error of the report.
+// @Tags book
+// @Accept multipart/form-data
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param file formData file true "CSV or JSONL file"
+// @Param format query string false "file format, guessed from the file name when omitted" Enums(csv, jsonl)
+// @Param dry_run query bool false "validate rows and resolve categories without writing anything"
+// @Param create_categories query bool false "create categories given by a name that does not exist yet, requires book_category:write"
+// @Param async query bool false "run as a background job, see /v1/jobs/{job_id}"
+// @Success 200 {object} models.ResponseModel{data=models.ImportReport} "every row was imported"
+// @Success 207 {object} models.ResponseModel{data=models.ImportReport} "some rows failed"
+// @Success 202 {object} models.ResponseModel{data=models.Job} "job submitted"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 503 {object} models.ResponseModel{error=models.ErrorModel} "Job Queue Full"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) ImportBook(c *gin.Context) {
+	opts, ok := h.parseImportOptions(c)
+	if !ok {
+		return
+	}
+
+	if opts.createCategories && !h.policy.Allowed(c.GetString(CtxUserType), rbac.PermissionBookCategoryWrite) {
+		h.handleErrorResponse(c, http.StatusForbidden, ErrForbidden, "permission denied: "+rbac.PermissionBookCategoryWrite)
+		return
+	}
+
+	part, format, ok := h.importFilePart(c, opts.format)
+	if !ok {
+		return
+	}
+
+	async, ok := h.parseAsync(c)
+	if !ok {
+		return
+	}
+
+	h.importUpload(c, jobBookImport, part, format, opts, async, h.importBooks)
+}
+
+// ImportBookCategory godoc
+// @ID import-book-category
+// @Router /v1/book_category/import [POST]
+// @Summary import book categories
+// @Description Import book categories from a CSV file with a header row or a JSON Lines file, with a name column.
+// @Description Rows are imported independently as they are read, so the import may partially succeed.
+// @Description Without async=true the import stops after MAX_SYNC_IMPORT_ROWS rows (1000 by default) or BATCH_TIMEOUT, with the reason in the error of the report.
+// @Tags book_category
+// @Accept multipart/form-data
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param file formData file true "CSV or JSONL file"
+// @Param format query string false "file format, guessed from the file name when omitted" Enums(csv, jsonl)
+// @Param dry_run query bool false "validate rows without writing anything"
+// @Param async query bool false "run as a background job, see /v1/jobs/{job_id}"
+// @Success 200 {object} models.ResponseModel{data=models.ImportReport} "every row was imported"
+// @Success 207 {object} models.ResponseModel{data=models.ImportReport} "some rows failed"
+// @Success 202 {object} models.ResponseModel{data=models.Job} "job submitted"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 403 {object} models.ResponseModel{error=models.ErrorModel} "Forbidden"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure 503 {object} models.ResponseModel{error=models.ErrorModel} "Job Queue Full"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) ImportBookCategory(c *gin.Context) {
+	opts, ok := h.parseImportOptions(c)
+	if !ok {
+		return
+	}
+
+	part, format, ok := h.importFilePart(c, opts.format)
+	if !ok {
+		return
+	}
+
+	async, ok := h.parseAsync(c)
+	if !ok {
+		return
+	}
+
+	h.importUpload(c, jobBookCategoryImport, part, format, opts, async, h.importBookCategories)
+}
+
+// importUpload imports the uploaded file. Synchronous imports read the rows straight from the request,
+// at most cfg.MaxSyncImportRows of them within cfg.BatchTimeout, so they are answered within the http write
+// timeout. Async imports spool the file under the job dir first, so the body is read in full within the
+// http read timeout rather than while rows are written upstream, and then run as a background job.
+func (h *handler) importUpload(c *gin.Context, kind string, part *multipart.Part, format string, opts importOptions, async bool, importFn func(context.Context, tabular.Reader, importOptions) models.ImportReport) {
+	if async {
+		spool, remove, ok := h.spoolUpload(c, part)
+		if !ok {
+			return
+		}
+
+		h.submitImport(c, kind, spool, remove, format, opts, importFn)
+		return
+	}
+
+	rows, err := tabular.NewReader(format, part)
+	if err != nil {
+		h.handleUploadError(c, "wrong input format", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.cfg.BatchTimeout)
+	defer cancel()
+
+	opts.maxRows = h.cfg.MaxSyncImportRows
+	h.handleImportResponse(c, importFn(ctx, rows, opts))
+}
+
+// spoolUpload copies an uploaded file part to an upload file of the job manager and returns its name,
+// along with the function removing it
+func (h *handler) spoolUpload(c *gin.Context, part *multipart.Part) (string, func(), bool) {
+	spool, remove, err := h.jobs.CreateUpload()
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while receiving file", err.Error())
+		return "", nil, false
+	}
+
+	_, err = io.Copy(spool, part)
+	if closeErr := spool.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		remove()
+		h.handleUploadError(c, "wrong input file", err)
+		return "", nil, false
+	}
+
+	return spool.Name(), remove, true
+}
+
+// handleUploadError answers 413 when the upload is larger than cfg.MaxImportSize and 400 otherwise
+func (h *handler) handleUploadError(c *gin.Context, message string, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.handleErrorResponse(c, http.StatusRequestEntityTooLarge, message, err.Error())
+		return
+	}
+	h.handleErrorResponse(c, http.StatusBadRequest, message, err.Error())
+}
+
+// importBooks creates a book for every row, resolving category names to ids on the way
+func (h *handler) importBooks(ctx context.Context, rows tabular.Reader, opts importOptions) models.ImportReport {
+	categories := &categoryResolver{h: h, create: opts.createCategories, dryRun: opts.dryRun, ids: make(map[string]string)}
+
+	report := h.runImport(ctx, rows, opts, func(ctx context.Context, row tabular.Row) (importWrite, error) {
+		book := models.ImportBookRow{Name: row["name"], Category_id: row["category_id"], Category: row["category"]}
+		if err := book.ValidateWithContext(h.validationContext(ctx)); err != nil {
+			return nil, err
+		}
+
+		if book.Category_id == "" {
+			id, err := categories.resolve(ctx, book.Category)
+			if err != nil {
+				return nil, err
+			}
+			book.Category_id = id
+		}
+
+		return func(ctx context.Context) error {
+			_, err := h.services.BookService().Create(
+				ctx,
+				&book_service.CreateBook{
+					Name:       book.Name,
+					CategoryId: book.Category_id,
+				},
+			)
+			return err
+		}, nil
+	})
+	report.CreatedCategories = categories.created
+
+	return report
+}
+
+// importBookCategories creates a book category for every row
+func (h *handler) importBookCategories(ctx context.Context, rows tabular.Reader, opts importOptions) models.ImportReport {
+	return h.runImport(ctx, rows, opts, func(ctx context.Context, row tabular.Row) (importWrite, error) {
+		category := models.CreateBookCategory{Name: row["name"]}
+		if err := category.Validate(); err != nil {
+			return nil, err
+		}
+
+		return func(ctx context.Context) error {
+			_, err := h.services.BookCategoryService().Create(
+				ctx,
+				&book_service.CreateBookCategory{
+					Name: category.Name,
+				},
+			)
+			return err
+		}, nil
+	})
+}
+
+// runImport reads rows one at a time and prepares each of them in order. The prepared writes run on
+// cfg.BatchConcurrency workers, each bounded by cfg.UpstreamTimeout since an import as a whole takes
+// as long as its file is. On a dry run the writes are skipped.
+func (h *handler) runImport(ctx context.Context, rows tabular.Reader, opts importOptions, prepare func(ctx context.Context, row tabular.Row) (importWrite, error)) models.ImportReport {
+	type task struct {
+		line  int
+		write importWrite
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		report = models.ImportReport{DryRun: opts.dryRun}
+		tasks  = make(chan task)
+	)
+
+	fail := func(line int, body models.ErrorModel) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		report.Failed++
+		if len(report.Errors) < maxImportErrors {
+			report.Errors = append(report.Errors, models.ImportRowError{Line: line, Error: body})
+		} else {
+			report.ErrorsTruncated = true
+		}
+	}
+	succeed := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		report.Imported++
+	}
+
+	for w := 0; w < h.cfg.BatchConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range tasks {
+				callCtx, cancel := context.WithTimeout(ctx, h.cfg.UpstreamTimeout)
+				err := t.write(callCtx)
+				cancel()
+
+				if err != nil {
+					_, body := grpcErrorModel(err)
+					fail(t.line, body)
+					continue
+				}
+				succeed()
+			}
+		}()
+	}
+
+	var readErr error
+	for ctx.Err() == nil {
+		if opts.progress != nil {
+			opts.progress(report.Rows)
+		}
+
+		row, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if opts.maxRows > 0 && report.Rows >= opts.maxRows {
+			readErr = fmt.Errorf("%w, stopped after %d rows", errTooManyImportRows, opts.maxRows)
+			break
+		}
+
+		var rowErr *tabular.RowError
+		if errors.As(err, &rowErr) {
+			report.Rows++
+			fail(rowErr.Line, models.ErrorModel{Code: ErrBadRequest, Message: rowErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+		report.Rows++
+
+		callCtx, cancel := context.WithTimeout(ctx, h.cfg.UpstreamTimeout)
+		write, err := prepare(callCtx, row)
+		cancel()
+
+		if err != nil {
+			fail(rows.Line(), importRowErrorModel(err))
+			continue
+		}
+		if opts.dryRun {
+			succeed()
+			continue
+		}
+
+		tasks <- task{line: rows.Line(), write: write}
+	}
+
+	close(tasks)
+	wg.Wait()
+
+	if opts.progress != nil {
+		opts.progress(report.Rows)
+	}
+
+	if readErr == nil {
+		readErr = ctx.Err()
+	}
+	if readErr != nil {
+		body := models.ErrorModel{Code: ErrBadRequest, Message: readErr.Error()}
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(readErr, &tooLarge) || errors.Is(readErr, errTooManyImportRows) {
+			body.Code = httpErrorCode(http.StatusRequestEntityTooLarge)
+		}
+		report.Error = &body
+	}
+
+	return report
+}
+
+// importRowErrorModel is the error body of a row rejected before it was written
+func importRowErrorModel(err error) models.ErrorModel {
+	if body, ok := validationErrorModel(err); ok {
+		return body
+	}
+	if _, ok := status.FromError(err); ok {
+		_, body := grpcErrorModel(err)
+		return body
+	}
+
+	return models.ErrorModel{Code: ErrBadRequest, Message: err.Error()}
+}
+
+// categoryResolver maps the category names of a book import to ids. It is only used from the
+// goroutine reading the rows, so it needs no locking.
+type categoryResolver struct {
+	h      *handler
+	create bool
+	dryRun bool
+	// ids is keyed by lower cased name, with "" for categories a dry run would create
+	ids     map[string]string
+	created []string
+}
+
+func (r *categoryResolver) resolve(ctx context.Context, name string) (string, error) {
+	key := strings.ToLower(name)
+	if id, ok := r.ids[key]; ok {
+		return id, nil
+	}
+
+	id, err := r.find(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if id == "" {
+		if !r.create {
+			return "", status.Errorf(codes.NotFound, "category %q does not exist, pass create_categories=true to create it", name)
+		}
+
+		if !r.dryRun {
+			resp, err := r.h.services.BookCategoryService().Create(ctx, &book_service.CreateBookCategory{Name: name})
+			if err != nil {
+				return "", err
+			}
+			id = resp.GetId()
+		}
+		r.created = append(r.created, name)
+	}
+
+	r.ids[key] = id
+	return id, nil
+}
+
+// find pages through the categories matching name for one with exactly that name, case insensitively
+func (r *categoryResolver) find(ctx context.Context, name string) (string, error) {
+	limit := r.h.cfg.MaxPageSize
+
+	for offset := 0; ; offset += limit {
+		resp, err := r.h.services.BookCategoryService().GetAll(
+			ctx,
+			&book_service.GetAllBookCategoryRequest{
+				Limit:  int32(limit),
+				Offset: int32(offset),
+				Name:   name,
+			},
+		)
+		if err != nil {
+			return "", err
+		}
+
+		for _, category := range resp.GetBookcategorylist() {
+			if strings.EqualFold(category.GetName(), name) {
+				return category.GetId(), nil
+			}
+		}
+
+		if offset+limit >= int(resp.GetCount()) || len(resp.GetBookcategorylist()) == 0 {
+			return "", nil
+		}
+	}
+}
+
+// parseImportOptions reads the format, dry_run and create_categories query params
+func (h *handler) parseImportOptions(c *gin.Context) (importOptions, bool) {
+	opts := importOptions{format: c.Query("format")}
+	if opts.format != "" && opts.format != tabular.CSV && opts.format != tabular.JSONL {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input format", "format must be csv or jsonl")
+		return opts, false
+	}
+
+	var err error
+	if opts.dryRun, err = strconv.ParseBool(c.DefaultQuery("dry_run", "false")); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input dry_run", err)
+		return opts, false
+	}
+	if opts.createCategories, err = strconv.ParseBool(c.DefaultQuery("create_categories", "false")); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input create_categories", err)
+		return opts, false
+	}
+
+	return opts, true
+}
+
+// importFilePart finds the "file" part of a multipart upload of at most cfg.MaxImportSize bytes
+// and settles its format
+func (h *handler) importFilePart(c *gin.Context, format string) (*multipart.Part, string, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.cfg.MaxImportSize)
+
+	parts, err := c.Request.MultipartReader()
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input file", err)
+		return nil, "", false
+	}
+
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			h.handleErrorResponse(c, http.StatusBadRequest, "wrong input file", "file is missing")
+			return nil, "", false
+		}
+		if err != nil {
+			h.handleErrorResponse(c, http.StatusBadRequest, "wrong input file", err)
+			return nil, "", false
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		if format == "" {
+			format = tabular.FormatFromFilename(part.FileName())
+		}
+		if format != tabular.CSV && format != tabular.JSONL {
+			h.handleErrorResponse(c, http.StatusBadRequest, "wrong input format", "format must be csv or jsonl")
+			return nil, "", false
+		}
+
+		return part, format, true
+	}
+}
+
+// handleImportResponse answers 200 when every row was imported and 207 Multi-Status otherwise
+func (h *handler) handleImportResponse(c *gin.Context, report models.ImportReport) {
+	if report.Failed > 0 || report.Error != nil {
+		h.handleSuccessResponse(c, http.StatusMultiStatus, "partially failed", report)
+		return
+	}
+	h.handleSuccessResponse(c, http.StatusOK, "ok", report)
+}
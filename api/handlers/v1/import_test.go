@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/config"
+	"book-api-gateway/pkg/tabular"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testImportPrepare rejects rows named "invalid" before writing them and fails the writes of rows named "taken"
+func testImportPrepare(written *sync.Map) func(ctx context.Context, row tabular.Row) (importWrite, error) {
+	return func(ctx context.Context, row tabular.Row) (importWrite, error) {
+		name := row["name"]
+		if name == "invalid" {
+			return nil, errors.New("name is invalid")
+		}
+
+		return func(ctx context.Context) error {
+			if name == "taken" {
+				return status.Error(codes.AlreadyExists, "name is taken")
+			}
+			written.Store(name, true)
+			return nil
+		}, nil
+	}
+}
+
+func TestRunImport(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		file        string
+		opts        importOptions
+		want        models.ImportReport
+		wantLines   []int
+		wantWritten []string
+		wantErrCode string
+	}{
+		{
+			name:        "every row imported",
+			format:      tabular.CSV,
+			file:        "name\na\nb\nc\n",
+			want:        models.ImportReport{Rows: 3, Imported: 3},
+			wantWritten: []string{"a", "b", "c"},
+		},
+		{
+			name:        "rejected, failed and malformed rows",
+			format:      tabular.JSONL,
+			file:        "{\"name\":\"a\"}\n{\"name\":\"invalid\"}\nnot json\n{\"name\":\"taken\"}\n",
+			want:        models.ImportReport{Rows: 4, Imported: 1, Failed: 3},
+			wantLines:   []int{2, 3, 4},
+			wantWritten: []string{"a"},
+		},
+		{
+			name:      "dry run writes nothing",
+			format:    tabular.CSV,
+			file:      "name\na\ninvalid\ntaken\n",
+			opts:      importOptions{dryRun: true},
+			want:      models.ImportReport{DryRun: true, Rows: 3, Imported: 2, Failed: 1},
+			wantLines: []int{3},
+		},
+		{
+			name:        "rows over the cap",
+			format:      tabular.CSV,
+			file:        "name\na\nb\nc\n",
+			opts:        importOptions{maxRows: 2},
+			want:        models.ImportReport{Rows: 2, Imported: 2},
+			wantWritten: []string{"a", "b"},
+			wantErrCode: httpErrorCode(http.StatusRequestEntityTooLarge),
+		},
+		{
+			name:        "rows exactly at the cap",
+			format:      tabular.CSV,
+			file:        "name\na\nb\n",
+			opts:        importOptions{maxRows: 2},
+			want:        models.ImportReport{Rows: 2, Imported: 2},
+			wantWritten: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, config.Config{BatchConcurrency: 2, UpstreamTimeout: time.Second})
+
+			rows, err := tabular.NewReader(tt.format, strings.NewReader(tt.file))
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+
+			var written sync.Map
+			report := h.runImport(context.Background(), rows, tt.opts, testImportPrepare(&written))
+
+			if report.DryRun != tt.want.DryRun || report.Rows != tt.want.Rows || report.Imported != tt.want.Imported || report.Failed != tt.want.Failed {
+				t.Fatalf("runImport() = %+v, want %+v", report, tt.want)
+			}
+
+			var lines []int
+			for _, rowErr := range report.Errors {
+				lines = append(lines, rowErr.Line)
+			}
+			sort.Ints(lines)
+			if fmt.Sprint(lines) != fmt.Sprint(tt.wantLines) {
+				t.Fatalf("runImport() failed lines = %v, want %v", lines, tt.wantLines)
+			}
+
+			var gotWritten []string
+			written.Range(func(key, _ interface{}) bool {
+				gotWritten = append(gotWritten, key.(string))
+				return true
+			})
+			sort.Strings(gotWritten)
+			if fmt.Sprint(gotWritten) != fmt.Sprint(tt.wantWritten) {
+				t.Fatalf("runImport() wrote %v, want %v", gotWritten, tt.wantWritten)
+			}
+
+			switch {
+			case tt.wantErrCode == "" && report.Error != nil:
+				t.Fatalf("runImport() error = %+v, want none", report.Error)
+			case tt.wantErrCode != "" && (report.Error == nil || report.Error.Code != tt.wantErrCode):
+				t.Fatalf("runImport() error = %+v, want code %s", report.Error, tt.wantErrCode)
+			}
+		})
+	}
+}
+
+func TestRunImportTruncatesErrors(t *testing.T) {
+	h := newTestHandler(t, config.Config{BatchConcurrency: 2, UpstreamTimeout: time.Second})
+
+	file := "name\n" + strings.Repeat("invalid\n", maxImportErrors+1)
+	rows, err := tabular.NewReader(tabular.CSV, strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	var written sync.Map
+	report := h.runImport(context.Background(), rows, importOptions{}, testImportPrepare(&written))
+
+	if report.Failed != maxImportErrors+1 || len(report.Errors) != maxImportErrors || !report.ErrorsTruncated {
+		t.Fatalf("runImport() failed = %d with %d errors, truncated %v, want %d with %d errors, truncated",
+			report.Failed, len(report.Errors), report.ErrorsTruncated, maxImportErrors+1, maxImportErrors)
+	}
+}
+
+func TestRunImportStopsWhenCanceled(t *testing.T) {
+	h := newTestHandler(t, config.Config{BatchConcurrency: 1, UpstreamTimeout: time.Second})
+
+	rows, err := tabular.NewReader(tabular.CSV, strings.NewReader("name\na\nb\nc\n"))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	report := h.runImport(ctx, rows, importOptions{}, func(ctx context.Context, row tabular.Row) (importWrite, error) {
+		cancel()
+		return func(ctx context.Context) error { return nil }, nil
+	})
+
+	if report.Rows != 1 || report.Error == nil {
+		t.Fatalf("runImport() = %+v, want it to stop after the first row with an error", report)
+	}
+}
+
+func TestImportBookCategorySync(t *testing.T) {
+	h := newTestHandler(t, config.Config{BatchConcurrency: 2, BatchTimeout: time.Second, UpstreamTimeout: time.Second, MaxImportSize: 1 << 10, MaxSyncImportRows: 2})
+
+	router := gin.New()
+	router.POST("/v1/book_category/import", h.ImportBookCategory)
+
+	tests := []struct {
+		name        string
+		filename    string
+		file        string
+		want        int
+		wantRows    int
+		wantErrCode string
+	}{
+		{name: "within the row cap", filename: "categories.csv", file: "name\na\nb\n", want: http.StatusOK, wantRows: 2},
+		{name: "over the row cap", filename: "categories.csv", file: "name\na\nb\nc\n", want: http.StatusMultiStatus, wantRows: 2, wantErrCode: httpErrorCode(http.StatusRequestEntityTooLarge)},
+		{name: "format from the file name", filename: "categories.jsonl", file: "{\"name\":\"a\"}\n", want: http.StatusOK, wantRows: 1},
+		{name: "unknown format", filename: "categories.txt", file: "a\n", want: http.StatusBadRequest},
+		{name: "over the max import size", filename: "categories.csv", file: "name\na\n" + strings.Repeat("b", 2<<10) + "\n", want: http.StatusMultiStatus, wantRows: 1, wantErrCode: httpErrorCode(http.StatusRequestEntityTooLarge)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			part, err := form.CreateFormFile("file", tt.filename)
+			if err != nil {
+				t.Fatalf("CreateFormFile() error = %v", err)
+			}
+			part.Write([]byte(tt.file))
+			form.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/book_category/import?dry_run=true", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.wantRows == 0 {
+				return
+			}
+
+			var resp struct {
+				Data models.ImportReport `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding %s: %v", rec.Body, err)
+			}
+			if resp.Data.Rows != tt.wantRows {
+				t.Fatalf("rows = %d, want %d", resp.Data.Rows, tt.wantRows)
+			}
+			if gotCode := ""; resp.Data.Error != nil {
+				gotCode = resp.Data.Error.Code
+				if gotCode != tt.wantErrCode {
+					t.Fatalf("error code = %q, want %q", gotCode, tt.wantErrCode)
+				}
+			} else if tt.wantErrCode != "" {
+				t.Fatalf("error = nil, want code %q", tt.wantErrCode)
+			}
+		})
+	}
+}
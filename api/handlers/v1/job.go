@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/pkg/jobs"
+	"book-api-gateway/pkg/tabular"
+	"book-api-gateway/storage"
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	jobBookImport         = "book_import"
+	jobBookCategoryImport = "book_category_import"
+	jobBookExport         = "book_export"
+	jobBookCategoryExport = "book_category_export"
+)
+
+// GetJob godoc
+// @ID get-job
+// @Router /v1/jobs/{job_id} [GET]
+// @Summary get job
+// @Description Get the status, progress and outcome of a background import or export.
+// @Description Finished jobs and their results are deleted JOB_TTL (24 hours by default) after they finish.
+// @Tags jobs
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param job_id path string true "job_id"
+// @Success 200 {object} models.ResponseModel{data=models.Job} "desc"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 404 {object} models.ResponseModel{error=models.ErrorModel} "Not Found"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) GetJob(c *gin.Context) {
+	job, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+
+	h.handleSuccessResponse(c, http.StatusOK, "ok", jobModel(job))
+}
+
+// GetJobResult godoc
+// @ID get-job-result
+// @Router /v1/jobs/{job_id}/result [GET]
+// @Summary download job result
+// @Description Download the file produced by a succeeded export job
+// @Tags jobs
+// @Produce text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,json,application/problem+json
+// @Security ApiKeyAuth
+// @Param job_id path string true "job_id"
+// @Success 200 {file} file "result"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 404 {object} models.ResponseModel{error=models.ErrorModel} "Not Found"
+// @Response 409 {object} models.ResponseModel{error=models.ErrorModel} "No Result"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) GetJobResult(c *gin.Context) {
+	job, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+
+	if job.Status != storage.JobSucceeded || job.ResultFile == "" {
+		h.handleErrorResponse(c, http.StatusConflict, "job has no result", "job is "+job.Status+" and has no downloadable result")
+		return
+	}
+
+	c.Header("Content-Type", job.ResultContentType)
+	c.FileAttachment(job.ResultFile, job.ResultName)
+}
+
+// CancelJob godoc
+// @ID cancel-job
+// @Router /v1/jobs/{job_id} [DELETE]
+// @Summary cancel job
+// @Description Cancel a queued or running job. A running job turns canceled once it stopped.
+// @Tags jobs
+// @Produce json,application/problem+json
+// @Security ApiKeyAuth
+// @Param job_id path string true "job_id"
+// @Success 202 {object} models.ResponseModel{data=models.Job} "desc"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Response 404 {object} models.ResponseModel{error=models.ErrorModel} "Not Found"
+// @Response 409 {object} models.ResponseModel{error=models.ErrorModel} "Already Finished"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) CancelJob(c *gin.Context) {
+	job, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobs.Cancel(c.Request.Context(), job.Id)
+	if errors.Is(err, jobs.ErrFinished) {
+		h.handleErrorResponse(c, http.StatusConflict, "job already finished", "job is "+job.Status)
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while canceling job", err.Error())
+		return
+	}
+
+	h.handleSuccessResponse(c, http.StatusAccepted, "canceling", jobModel(job))
+}
+
+// getJob loads the job of the job_id path param. Jobs are only visible to whoever submitted them
+// and to superadmins; other callers get the same 404 as for unknown jobs.
+func (h *handler) getJob(c *gin.Context) (*storage.Job, bool) {
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("job_id"))
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while getting job", err.Error())
+		return nil, false
+	}
+
+	if errors.Is(err, storage.ErrNotFound) || (job.Owner != jobOwner(c) && !isSuperAdminUser(c)) {
+		h.handleErrorResponse(c, http.StatusNotFound, "job not found", storage.ErrNotFound.Error())
+		return nil, false
+	}
+
+	return job, true
+}
+
+// parseAsync reads the "async" query param which turns imports and exports into background jobs
+func (h *handler) parseAsync(c *gin.Context) (bool, bool) {
+	async, err := strconv.ParseBool(c.DefaultQuery("async", "false"))
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input async", err)
+		return false, false
+	}
+
+	return async, true
+}
+
+// submitImport runs importFn on a spooled upload as a background job, which calls remove when it is done
+func (h *handler) submitImport(c *gin.Context, kind, spool string, remove func(), format string, opts importOptions, importFn func(context.Context, tabular.Reader, importOptions) models.ImportReport) {
+	job, err := h.jobs.Submit(c.Request.Context(), kind, jobOwner(c), func(ctx context.Context, run *jobs.Run) error {
+		f, err := os.Open(spool)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		rows, err := tabular.NewReader(format, f)
+		if err != nil {
+			return err
+		}
+
+		opts.progress = func(rows int) { run.Progress(rows, 0) }
+		report := importFn(ctx, rows, opts)
+		if err := run.SetSummary(report); err != nil {
+			return err
+		}
+		if report.Error != nil {
+			return errors.New(report.Error.Message)
+		}
+
+		return nil
+	}, remove)
+
+	h.handleJobSubmitted(c, job, err)
+}
+
+// submitExport runs an export as a background job writing to a downloadable result file
+func (h *handler) submitExport(c *gin.Context, kind, filename, format string, columns []string, page exportPage) {
+	job, err := h.jobs.Submit(c.Request.Context(), kind, jobOwner(c), func(ctx context.Context, run *jobs.Run) error {
+		f, err := run.CreateResult(filename+"."+format, tabular.ContentType(format))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		tracked := func(ctx context.Context, limit, offset int) ([][]string, int, error) {
+			rows, count, err := page(ctx, limit, offset)
+			if err == nil {
+				run.Progress(offset+len(rows), count)
+			}
+			return rows, count, err
+		}
+
+		pageCtx, cancel := context.WithTimeout(ctx, h.cfg.UpstreamTimeout)
+		first, count, err := tracked(pageCtx, h.cfg.MaxPageSize, 0)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		buf := bufio.NewWriter(f)
+		w, err := tabular.NewWriter(format, buf, columns)
+		if err != nil {
+			return err
+		}
+		if err := h.exportAll(ctx, w, tracked, first, count, func() {}); err != nil {
+			return err
+		}
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+
+		return f.Close()
+	}, nil)
+
+	h.handleJobSubmitted(c, job, err)
+}
+
+// handleJobSubmitted answers 202 with the queued job and its location, or 503 when the queue is full
+func (h *handler) handleJobSubmitted(c *gin.Context, job *storage.Job, err error) {
+	if errors.Is(err, jobs.ErrQueueFull) {
+		h.handleErrorResponse(c, http.StatusServiceUnavailable, ErrServiceUnavailable, err.Error())
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while submitting job", err.Error())
+		return
+	}
+
+	c.Header("Location", "/v1/jobs/"+job.Id)
+	h.handleSuccessResponse(c, http.StatusAccepted, "accepted", jobModel(job))
+}
+
+// jobOwner identifies the caller a job belongs to
+func jobOwner(c *gin.Context) string {
+	if id := c.GetString(CtxApiKeyID); id != "" {
+		return "api_key:" + id
+	}
+
+	return c.GetString(CtxUserID)
+}
+
+// isSuperAdminUser reports whether a superadmin user, not an api key minted by one, is calling
+func isSuperAdminUser(c *gin.Context) bool {
+	return c.GetString(CtxApiKeyID) == "" && c.GetString(CtxUserType) == SuperAdminUserType
+}
+
+func jobModel(job *storage.Job) models.Job {
+	m := models.Job{
+		Id:         job.Id,
+		Kind:       job.Kind,
+		Status:     job.Status,
+		Done:       job.Done,
+		Total:      job.Total,
+		Error:      job.Error,
+		Summary:    job.Summary,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Status == storage.JobSucceeded && job.ResultFile != "" {
+		m.ResultUrl = "/v1/jobs/" + job.Id + "/result"
+	}
+
+	return m
+}
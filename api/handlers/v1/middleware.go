@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"book-api-gateway/pkg/helper"
+	"book-api-gateway/pkg/jwt"
+	"book-api-gateway/pkg/logger"
+	"book-api-gateway/pkg/ratelimit"
+	"book-api-gateway/storage"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// CtxUserID is the gin.Context key holding the authenticated user id
+	CtxUserID = "user_id"
+	// CtxUserType is the gin.Context key holding the authenticated user type
+	CtxUserType = "user_type"
+	// CtxApiKeyID is the gin.Context key holding the id of the api key the caller authenticated with
+	CtxApiKeyID = "api_key_id"
+	// CtxRequestID is the gin.Context key holding the request id, also returned in the X-Request-Id header
+	CtxRequestID = "request_id"
+)
+
+// connCtxKey is the request context key holding the net.Conn a request arrived on, see ConnContext
+type connCtxKey struct{}
+
+// ConnContext is the http.Server ConnContext hook which puts the connection on the context of its requests
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connCtxKey{}, conn)
+}
+
+var (
+	errInvalidApiKey = errors.New("api key is invalid")
+	errExpiredApiKey = errors.New("api key is expired")
+)
+
+// apiKeyForbiddenPaths are route prefixes api keys can never be scoped for, whatever the role of their creator,
+// so a key can neither mint further keys nor reach the admin endpoints
+var apiKeyForbiddenPaths = []string{"/v1/api_keys", "/v1/admin"}
+
+// AuthMiddleware rejects requests without a valid bearer token or api key and puts the caller identity on the context
+func (h *handler) AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key, ok := apiKeyFromRequest(c); ok {
+			h.authenticateApiKey(c, key)
+			return
+		}
+
+		token, err := jwt.BearerToken(c.GetHeader("Authorization"))
+		if err != nil {
+			h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, err.Error())
+			c.Abort()
+			return
+		}
+
+		claims, err := jwt.ExtractClaims(token, []byte(h.cfg.SecretKey))
+		if err != nil {
+			h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, err.Error())
+			c.Abort()
+			return
+		}
+		if claims.TokenType != jwt.AccessToken {
+			h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, jwt.ErrInvalidToken.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set(CtxUserID, claims.UserID)
+		c.Set(CtxUserType, claims.UserType)
+		c.Next()
+	}
+}
+
+// authenticateApiKey verifies a "<id>.<secret>" api key, its expiry and that its scopes cover the route
+func (h *handler) authenticateApiKey(c *gin.Context, rawKey string) {
+	id, secret, found := strings.Cut(rawKey, ".")
+	if !found || id == "" || secret == "" {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, errInvalidApiKey.Error())
+		c.Abort()
+		return
+	}
+
+	key, err := h.storage.ApiKey().GetById(c.Request.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, errInvalidApiKey.Error())
+		c.Abort()
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while getting api key", err.Error())
+		c.Abort()
+		return
+	}
+
+	if err := helper.ComparePasswordHash(key.SecretHash, secret); err != nil {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, errInvalidApiKey.Error())
+		c.Abort()
+		return
+	}
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now()) {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, errExpiredApiKey.Error())
+		c.Abort()
+		return
+	}
+
+	c.Set(CtxApiKeyID, key.Id)
+	c.Set(CtxUserType, key.Role)
+	if !apiKeyAllows(key, c.Request.Method, c.FullPath()) {
+		h.handleErrorResponse(c, http.StatusForbidden, ErrForbidden, "api key is not scoped for this route")
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// Authorize rejects callers whose user type lacks the permission routePolicies requires for the route.
+// Api key callers are checked against the role of the key's creator, on top of the scopes AuthMiddleware checked.
+func (h *handler) Authorize(routePolicies map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permission, ok := routePolicies[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if !h.policy.Allowed(c.GetString(CtxUserType), permission) {
+			h.handleErrorResponse(c, http.StatusForbidden, ErrForbidden, "permission denied: "+permission)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimit takes a token from the caller's bucket for the route and rejects the request with 429 once it is empty.
+// Callers are told apart by user, api key or, before authentication, client ip. Routes listed in RATE_LIMITS get
+// their own bucket while every other route shares the default one. Requests are let through when the store fails.
+func (h *handler) RateLimit() gin.HandlerFunc {
+	defaultLimit, _ := ratelimit.ParseLimit(h.cfg.RateLimitDefault)
+	routeLimits := map[string]ratelimit.Limit{}
+	for route, value := range h.cfg.RateLimits {
+		routeLimits[route], _ = ratelimit.ParseLimit(value)
+	}
+
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		limit, ok := routeLimits[route]
+		if !ok {
+			limit, route = defaultLimit, "*"
+		}
+		if limit.Burst == 0 {
+			c.Next()
+			return
+		}
+
+		if !h.takeRateLimit(c, route+"|"+rateLimitSubject(c), limit) {
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// IPRateLimit takes a token from the client ip's bucket for every request before it is authenticated, so callers
+// without valid credentials can't make the gateway spend unlimited time checking tokens and api keys.
+// It is unlimited when RATE_LIMIT_IP is empty and lets requests through when the store fails.
+func (h *handler) IPRateLimit() gin.HandlerFunc {
+	limit, _ := ratelimit.ParseLimit(h.cfg.RateLimitIP)
+
+	return func(c *gin.Context) {
+		if limit.Burst == 0 {
+			c.Next()
+			return
+		}
+
+		if !h.takeRateLimit(c, "ip|ip:"+c.ClientIP(), limit) {
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// takeRateLimit takes a token from the bucket under key, sets the rate limit headers and writes the 429 response
+// when the bucket is empty. It reports whether the request may go ahead.
+func (h *handler) takeRateLimit(c *gin.Context, key string, limit ratelimit.Limit) bool {
+	res, err := h.limiter.Take(c.Request.Context(), key, limit)
+	if err != nil {
+		h.log.Error("error while taking rate limit token", logger.String("key", key), logger.Error(err))
+		return true
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, ceilSeconds(limit.Period)))
+
+	if !res.Allowed {
+		retryAfter := ceilSeconds(res.RetryAfter)
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		h.handleErrorResponse(c, http.StatusTooManyRequests, ErrTooManyRequests, fmt.Sprintf("rate limit of %s exceeded, retry in %ds", limit, retryAfter))
+		return false
+	}
+
+	return true
+}
+
+// RequestID reuses the caller's X-Request-Id or generates one, and echoes it on the response
+func (h *handler) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-Id")
+		if id == "" || len(id) > 128 {
+			b := make([]byte, 16)
+			if _, err := rand.Read(b); err == nil {
+				id = hex.EncodeToString(b)
+			}
+		}
+
+		c.Set(CtxRequestID, id)
+		c.Header("X-Request-Id", id)
+		c.Next()
+	}
+}
+
+// RequestLogger logs every request together with the user or api key that made it
+func (h *handler) RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		h.log.Info("request",
+			logger.String("method", c.Request.Method),
+			logger.String("path", c.Request.URL.Path),
+			logger.Int("status", c.Writer.Status()),
+			logger.String("latency", time.Since(start).String()),
+			logger.String("client_ip", c.ClientIP()),
+			logger.String("user_id", c.GetString(CtxUserID)),
+			logger.String("api_key_id", c.GetString(CtxApiKeyID)),
+			logger.String("request_id", c.GetString(CtxRequestID)),
+		)
+	}
+}
+
+// apiKeyFromRequest reads an api key from the X-API-Key header or an "Authorization: ApiKey <key>" header
+func apiKeyFromRequest(c *gin.Context) (string, bool) {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key, true
+	}
+
+	scheme, key, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "ApiKey") {
+		return strings.TrimSpace(key), true
+	}
+
+	return "", false
+}
+
+// rateLimitSubject names whose bucket a request takes from
+func rateLimitSubject(c *gin.Context) string {
+	if id := c.GetString(CtxApiKeyID); id != "" {
+		return "api_key:" + id
+	}
+	if id := c.GetString(CtxUserID); id != "" {
+		return "user:" + id
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds d up to whole seconds as rate limit headers expect
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+func apiKeyAllows(key *storage.ApiKey, method, path string) bool {
+	if apiKeyForbiddenPath(path) {
+		return false
+	}
+
+	for _, scope := range key.Scopes {
+		if scope.Method != "*" && scope.Method != method {
+			continue
+		}
+		if scope.Path == path || (strings.HasSuffix(scope.Path, "*") && strings.HasPrefix(path, strings.TrimSuffix(scope.Path, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// apiKeyForbiddenPath reports whether path, a route or a scope pattern, falls under apiKeyForbiddenPaths
+func apiKeyForbiddenPath(path string) bool {
+	for _, prefix := range apiKeyForbiddenPaths {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"book-api-gateway/config"
+	"book-api-gateway/pkg/helper"
+	"book-api-gateway/pkg/jwt"
+	"book-api-gateway/pkg/logger"
+	"book-api-gateway/pkg/rbac"
+	"book-api-gateway/storage"
+	"book-api-gateway/storage/memory"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestHandler returns a handler over cfg with the default rbac policy and empty in-memory storage
+func newTestHandler(t *testing.T, cfg config.Config) *handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	strg, err := memory.NewStorage("", nil)
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+
+	return NewHandler(&HandlerOptions{
+		Log:     logger.New(logger.LevelFatal, "test"),
+		Cfg:     cfg,
+		Policy:  rbac.DefaultPolicy(),
+		Storage: strg,
+	})
+}
+
+// serve sends a request without a body to router and returns the response status
+func serve(router http.Handler, method, path string, header http.Header) int {
+	req := httptest.NewRequest(method, path, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestAuthorize(t *testing.T) {
+	h := newTestHandler(t, config.Config{})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(CtxUserType, c.GetHeader("X-Test-User-Type"))
+	}, h.Authorize(map[string]string{
+		"POST /v1/book":                 rbac.PermissionBookWrite,
+		"DELETE /v1/book/:book_id":      rbac.PermissionBookWrite,
+		"GET /v1/admin/upstreams/:name": rbac.PermissionAdminRead,
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/v1/book", ok)
+	router.POST("/v1/book", ok)
+	router.DELETE("/v1/book/:book_id", ok)
+	router.GET("/v1/admin/upstreams/:name", ok)
+
+	tests := []struct {
+		name     string
+		userType string
+		method   string
+		path     string
+		want     int
+	}{
+		{name: "route without a policy", userType: "user", method: http.MethodGet, path: "/v1/book", want: http.StatusOK},
+		{name: "role with the permission", userType: "admin", method: http.MethodPost, path: "/v1/book", want: http.StatusOK},
+		{name: "role without the permission", userType: "user", method: http.MethodPost, path: "/v1/book", want: http.StatusForbidden},
+		{name: "unknown role", userType: "root", method: http.MethodPost, path: "/v1/book", want: http.StatusForbidden},
+		{name: "no role", method: http.MethodPost, path: "/v1/book", want: http.StatusForbidden},
+		{name: "route with params", userType: "user", method: http.MethodDelete, path: "/v1/book/9b2f1c3e-5d4a-4f6b-8c7d-0e1f2a3b4c5d", want: http.StatusForbidden},
+		{name: "permission held by superadmin only", userType: "admin", method: http.MethodGet, path: "/v1/admin/upstreams/book", want: http.StatusForbidden},
+		{name: "superadmin holds every permission", userType: "superadmin", method: http.MethodGet, path: "/v1/admin/upstreams/book", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serve(router, tt.method, tt.path, http.Header{"X-Test-User-Type": {tt.userType}})
+			if got != tt.want {
+				t.Fatalf("%s %s as %q status = %d, want %d", tt.method, tt.path, tt.userType, got, tt.want)
+			}
+		})
+	}
+}
+
+const testSecretKey = "test-secret-key"
+
+// mustToken signs tokenType claims for a user of userType with key
+func mustToken(t *testing.T, userType, tokenType string, key string) string {
+	t.Helper()
+
+	claims, err := jwt.NewClaims("user-1", userType, tokenType, time.Minute)
+	if err != nil {
+		t.Fatalf("NewClaims() error = %v", err)
+	}
+	token, err := jwt.GenerateJWT(claims, []byte(key))
+	if err != nil {
+		t.Fatalf("GenerateJWT() error = %v", err)
+	}
+
+	return token
+}
+
+// mustCreateApiKey stores key with the bcrypt hash of secret and returns the "<id>.<secret>" credential
+func mustCreateApiKey(t *testing.T, h *handler, key storage.ApiKey, secret string) string {
+	t.Helper()
+
+	hash, err := helper.GeneratePasswordHash(secret)
+	if err != nil {
+		t.Fatalf("GeneratePasswordHash() error = %v", err)
+	}
+	key.SecretHash = string(hash)
+	if err := h.storage.ApiKey().Create(context.Background(), &key); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	return key.Id + "." + secret
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	h := newTestHandler(t, config.Config{SecretKey: testSecretKey})
+
+	expired := time.Now().Add(-time.Minute)
+	scoped := mustCreateApiKey(t, h, storage.ApiKey{Id: "scoped", Role: "admin", Scopes: []storage.ApiKeyScope{{Method: http.MethodGet, Path: "/v1/book"}}}, "s3cret")
+	wildcard := mustCreateApiKey(t, h, storage.ApiKey{Id: "wildcard", Role: "superadmin", Scopes: []storage.ApiKeyScope{{Method: "*", Path: "/v1/*"}}}, "s3cret")
+	expiredKey := mustCreateApiKey(t, h, storage.ApiKey{Id: "expired", Role: "admin", Scopes: []storage.ApiKeyScope{{Method: "*", Path: "/v1/*"}}, ExpiresAt: &expired}, "s3cret")
+
+	router := gin.New()
+	v1 := router.Group("/v1", h.AuthMiddleware())
+	whoami := func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(CtxUserType)+"|"+c.GetString(CtxApiKeyID))
+	}
+	v1.GET("/book", whoami)
+	v1.POST("/book", whoami)
+	v1.GET("/api_keys", whoami)
+	v1.GET("/api_keys/:api_key_id", whoami)
+	v1.GET("/api_keys_archive", whoami)
+	v1.GET("/admin/upstreams/:name", whoami)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   http.Header
+		want     int
+		wantBody string
+	}{
+		{name: "no credentials", method: http.MethodGet, path: "/v1/book", want: http.StatusUnauthorized},
+		{name: "not a bearer token", method: http.MethodGet, path: "/v1/book", header: http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}}, want: http.StatusUnauthorized},
+		{name: "access token", method: http.MethodGet, path: "/v1/book", header: http.Header{"Authorization": {"Bearer " + mustToken(t, "admin", jwt.AccessToken, testSecretKey)}}, want: http.StatusOK, wantBody: "admin|"},
+		{name: "refresh token", method: http.MethodGet, path: "/v1/book", header: http.Header{"Authorization": {"Bearer " + mustToken(t, "admin", jwt.RefreshToken, testSecretKey)}}, want: http.StatusUnauthorized},
+		{name: "token signed with another key", method: http.MethodGet, path: "/v1/book", header: http.Header{"Authorization": {"Bearer " + mustToken(t, "admin", jwt.AccessToken, "another-key")}}, want: http.StatusUnauthorized},
+		{name: "api key in X-API-Key", method: http.MethodGet, path: "/v1/book", header: http.Header{"X-Api-Key": {scoped}}, want: http.StatusOK, wantBody: "admin|scoped"},
+		{name: "api key in Authorization", method: http.MethodGet, path: "/v1/book", header: http.Header{"Authorization": {"ApiKey " + scoped}}, want: http.StatusOK, wantBody: "admin|scoped"},
+		{name: "api key outside its scopes", method: http.MethodPost, path: "/v1/book", header: http.Header{"X-Api-Key": {scoped}}, want: http.StatusForbidden},
+		{name: "api key with a wrong secret", method: http.MethodGet, path: "/v1/book", header: http.Header{"X-Api-Key": {"scoped.wrong"}}, want: http.StatusUnauthorized},
+		{name: "unknown api key", method: http.MethodGet, path: "/v1/book", header: http.Header{"X-Api-Key": {"missing.s3cret"}}, want: http.StatusUnauthorized},
+		{name: "api key without a secret", method: http.MethodGet, path: "/v1/book", header: http.Header{"X-Api-Key": {"scoped"}}, want: http.StatusUnauthorized},
+		{name: "expired api key", method: http.MethodGet, path: "/v1/book", header: http.Header{"X-Api-Key": {expiredKey}}, want: http.StatusUnauthorized},
+		{name: "wildcard api key", method: http.MethodPost, path: "/v1/book", header: http.Header{"X-Api-Key": {wildcard}}, want: http.StatusOK, wantBody: "superadmin|wildcard"},
+		{name: "wildcard api key minting keys", method: http.MethodGet, path: "/v1/api_keys", header: http.Header{"X-Api-Key": {wildcard}}, want: http.StatusForbidden},
+		{name: "wildcard api key reading a key", method: http.MethodGet, path: "/v1/api_keys/scoped", header: http.Header{"X-Api-Key": {wildcard}}, want: http.StatusForbidden},
+		{name: "wildcard api key on admin routes", method: http.MethodGet, path: "/v1/admin/upstreams/book", header: http.Header{"X-Api-Key": {wildcard}}, want: http.StatusForbidden},
+		{name: "wildcard api key on a route sharing a forbidden prefix", method: http.MethodGet, path: "/v1/api_keys_archive", header: http.Header{"X-Api-Key": {wildcard}}, want: http.StatusOK, wantBody: "superadmin|wildcard"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("%s %s status = %d, want %d, body %s", tt.method, tt.path, rec.Code, tt.want, rec.Body)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Fatalf("%s %s identity = %q, want %q", tt.method, tt.path, rec.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestApiKeyAllows(t *testing.T) {
+	key := &storage.ApiKey{Scopes: []storage.ApiKeyScope{
+		{Method: http.MethodGet, Path: "/v1/book"},
+		{Method: "*", Path: "/v1/book_category*"},
+		{Method: "*", Path: "/v1/api_keys"},
+		{Method: "*", Path: "/v1/admin*"},
+	}}
+
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{method: http.MethodGet, path: "/v1/book", want: true},
+		{method: http.MethodPost, path: "/v1/book", want: false},
+		{method: http.MethodGet, path: "/v1/book/:book_id", want: false},
+		{method: http.MethodDelete, path: "/v1/book_category/:book_category_id", want: true},
+		{method: http.MethodGet, path: "/v1/api_keys", want: false},
+		{method: http.MethodGet, path: "/v1/admin/upstreams", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if got := apiKeyAllows(key, tt.method, tt.path); got != tt.want {
+				t.Fatalf("apiKeyAllows(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApiKeyForbiddenPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/v1/api_keys", want: true},
+		{path: "/v1/api_keys/:api_key_id", want: true},
+		{path: "/v1/admin", want: true},
+		{path: "/v1/admin/upstreams", want: true},
+		{path: "/v1/api_keys_archive", want: false},
+		{path: "/v1/administrators", want: false},
+		{path: "/v1/book", want: false},
+		{path: "/v1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := apiKeyForbiddenPath(tt.path); got != tt.want {
+				t.Fatalf("apiKeyForbiddenPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
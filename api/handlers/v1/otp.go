@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"book-api-gateway/api/models"
+	"book-api-gateway/pkg/helper"
+	"book-api-gateway/pkg/otp"
+	"book-api-gateway/pkg/util"
+	"book-api-gateway/storage"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidRecipient = errors.New("recipient must be a phone number (+998XXXXXXXXX) or an email address")
+	errInvalidOTP       = errors.New("code is invalid or expired")
+)
+
+// SendOTP godoc
+// @ID send-otp
+// @Router /v1/otp/send [POST]
+// @Summary send one-time code
+// @Description Send a one-time verification code to a phone number or email address.
+// @Description A new code is sent at most once per OTP_RESEND_COOLDOWN; earlier requests get the same answer and the pending code stays valid.
+// @Tags auth
+// @Accept json
+// @Produce json,application/problem+json
+// @Param recipient body models.SendOTPRequest true "recipient"
+// @Success 200 {object} models.ResponseModel{data=models.MsgModel} "desc"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) SendOTP(c *gin.Context) {
+	var req models.SendOTPRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for otp", err.Error())
+		return
+	}
+
+	channel, recipient, err := parseRecipient(req.Recipient)
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for otp", err.Error())
+		return
+	}
+
+	// unknown recipients get the same answer so the endpoint cannot be used to enumerate users
+	if _, err := h.userByRecipient(c, channel, recipient); errors.Is(err, storage.ErrNotFound) {
+		h.handleSuccessResponse(c, http.StatusOK, "sent", models.MsgModel{Msg: "Sent"})
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while getting user", err.Error())
+		return
+	}
+
+	code := helper.GenerateCode(h.cfg.OTPLength)
+	now := time.Now()
+	err = h.storage.OTP().Save(c.Request.Context(), &storage.OTP{
+		Recipient: recipient,
+		Code:      code,
+		SentAt:    now,
+		ExpiresAt: now.Add(h.cfg.OTPTTL),
+	}, h.cfg.OTPResendCooldown)
+	// answered like a sent code too, as a distinct answer would only exist for known recipients
+	if errors.Is(err, storage.ErrCooldown) {
+		h.handleSuccessResponse(c, http.StatusOK, "sent", models.MsgModel{Msg: "Sent"})
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while saving otp", err.Error())
+		return
+	}
+
+	if err := h.otpSender.Send(c.Request.Context(), channel, recipient, code); err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while sending otp", err.Error())
+		return
+	}
+
+	h.handleSuccessResponse(c, http.StatusOK, "sent", models.MsgModel{Msg: "Sent"})
+}
+
+// VerifyOTP godoc
+// @ID verify-otp
+// @Router /v1/otp/verify [POST]
+// @Summary verify one-time code
+// @Description Verify a one-time code and exchange it for an access and refresh token pair
+// @Tags auth
+// @Accept json
+// @Produce json,application/problem+json
+// @Param otp body models.VerifyOTPRequest true "otp"
+// @Success 200 {object} models.ResponseModel{data=models.TokenResponse} "desc"
+// @Response 400 {object} models.ResponseModel{error=models.ErrorModel} "Bad Request"
+// @Response 401 {object} models.ResponseModel{error=models.ErrorModel} "Unauthorized"
+// @Failure 500 {object} models.ResponseModel{error=models.ErrorModel} "Server Error"
+// @Failure default {object} models.ProblemDetails "application/problem+json error"
+func (h *handler) VerifyOTP(c *gin.Context) {
+	var req models.VerifyOTPRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for otp", err.Error())
+		return
+	}
+
+	channel, recipient, err := parseRecipient(req.Recipient)
+	if err != nil {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input for otp", err.Error())
+		return
+	}
+
+	// the attempt is counted before the code is compared, so concurrent guesses can't get past the limit.
+	// An exhausted code is kept until it expires so that asking for a new one is still subject to the cooldown.
+	pending, err := h.storage.OTP().IncrementAttempts(c.Request.Context(), recipient)
+	if errors.Is(err, storage.ErrNotFound) {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, errInvalidOTP.Error())
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while updating otp", err.Error())
+		return
+	}
+
+	if pending.Attempts > h.cfg.OTPMaxAttempts || subtle.ConstantTimeCompare([]byte(pending.Code), []byte(req.Code)) != 1 {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, errInvalidOTP.Error())
+		return
+	}
+
+	if err := h.storage.OTP().Delete(c.Request.Context(), recipient); err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while deleting otp", err.Error())
+		return
+	}
+
+	user, err := h.userByRecipient(c, channel, recipient)
+	if errors.Is(err, storage.ErrNotFound) {
+		h.handleErrorResponse(c, http.StatusUnauthorized, ErrUnauthorized, "user no longer exists")
+		return
+	} else if err != nil {
+		h.handleErrorResponse(c, http.StatusInternalServerError, "error while getting user", err.Error())
+		return
+	}
+
+	h.issueTokens(c, user)
+}
+
+func (h *handler) userByRecipient(c *gin.Context, channel, recipient string) (*storage.User, error) {
+	if channel == otp.ChannelSMS {
+		return h.storage.User().GetByPhone(c.Request.Context(), recipient)
+	}
+
+	return h.storage.User().GetByEmail(c.Request.Context(), recipient)
+}
+
+// parseRecipient detects whether recipient is a phone number or an email and normalizes it.
+// Phone numbers are accepted with or without the leading "+" and always returned with it.
+func parseRecipient(recipient string) (channel string, normalized string, err error) {
+	recipient = strings.TrimSpace(recipient)
+
+	switch {
+	case util.IsValidPhone(recipient):
+		return otp.ChannelSMS, recipient, nil
+	case !strings.HasPrefix(recipient, "+") && helper.ValidatePhoneNumber(recipient) == nil && util.IsValidPhone("+"+recipient):
+		return otp.ChannelSMS, "+" + recipient, nil
+	case util.IsValidEmail(recipient):
+		return otp.ChannelEmail, strings.ToLower(recipient), nil
+	default:
+		return "", "", errInvalidRecipient
+	}
+}
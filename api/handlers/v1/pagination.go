@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"book-api-gateway/pkg/cursor"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errCursorFilterMismatch = errors.New("cursor was issued for different filters")
+
+// page is the window of a listing requested by a client
+type page struct {
+	limit  int
+	offset int
+	filter string
+}
+
+// parsePage reads the page from a signed "cursor" query param, or from the legacy limit/offset params.
+// filter fingerprints the listing filters so a cursor cannot be replayed against another listing.
+func (h *handler) parsePage(c *gin.Context, filter string) (page, bool) {
+	if token := c.Query("cursor"); token != "" {
+		cur, err := cursor.Decode(token, []byte(h.cfg.SecretKey))
+		if err == nil && cur.Filter != filter {
+			err = errCursorFilterMismatch
+		}
+		if err != nil {
+			h.handleErrorResponse(c, http.StatusBadRequest, "wrong input cursor", err)
+			return page{}, false
+		}
+
+		// cursors issued before MaxPageSize was lowered still honour the current bound
+		if cur.Limit > h.cfg.MaxPageSize {
+			cur.Limit = h.cfg.MaxPageSize
+		}
+
+		return page{limit: cur.Limit, offset: cur.Offset, filter: filter}, true
+	}
+
+	limit, err := h.ParseQueryParam(c, "limit", strconv.Itoa(h.cfg.DefaultPageSize))
+	if err != nil {
+		return page{}, false
+	}
+	if limit < 1 || limit > h.cfg.MaxPageSize {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input limit", fmt.Sprintf("limit must be between 1 and %d", h.cfg.MaxPageSize))
+		return page{}, false
+	}
+
+	offset, err := h.ParseQueryParam(c, "offset", "0")
+	if err != nil {
+		return page{}, false
+	}
+	if offset < 0 {
+		h.handleErrorResponse(c, http.StatusBadRequest, "wrong input offset", "offset cannot be negative")
+		return page{}, false
+	}
+
+	return page{limit: limit, offset: offset, filter: filter}, true
+}
+
+// paginate returns the next/prev cursors around p for a listing of count items
+// and advertises them in an RFC 8288 Link header
+func (h *handler) paginate(c *gin.Context, p page, count int) (next string, prev string) {
+	var links []string
+
+	if p.offset+p.limit < count {
+		next = cursor.Encode(cursor.Cursor{Offset: p.offset + p.limit, Limit: p.limit, Filter: p.filter}, []byte(h.cfg.SecretKey))
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(c, next)))
+	}
+
+	if p.offset > 0 {
+		prevOffset := p.offset - p.limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		prev = cursor.Encode(cursor.Cursor{Offset: prevOffset, Limit: p.limit, Filter: p.filter}, []byte(h.cfg.SecretKey))
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(c, prev)))
+	}
+
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+
+	return next, prev
+}
+
+// pageURL is the current request URL with limit/offset replaced by the given cursor
+func pageURL(c *gin.Context, token string) string {
+	query := c.Request.URL.Query()
+	query.Del("limit")
+	query.Del("offset")
+	query.Set("cursor", token)
+
+	u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+	return u.String()
+}
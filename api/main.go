@@ -4,8 +4,15 @@ import (
 	"book-api-gateway/api/docs"
 	"book-api-gateway/api/handlers/v1"
 	"book-api-gateway/config"
+	"book-api-gateway/pkg/jobs"
 	"book-api-gateway/pkg/logger"
+	"book-api-gateway/pkg/otp"
+	"book-api-gateway/pkg/ratelimit"
+	"book-api-gateway/pkg/rbac"
 	"book-api-gateway/services"
+	"book-api-gateway/storage"
+	"context"
+	"net"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -14,18 +21,47 @@ import (
 )
 
 type RouterOptions struct {
-	Log      logger.Logger
-	Cfg      config.Config
-	Services services.ServicesI
+	Log       logger.Logger
+	Cfg       config.Config
+	Services  services.ServicesI
+	Policy    *rbac.Policy
+	Storage   storage.StorageI
+	OTPSender otp.Sender
+	Jobs      *jobs.Manager
+	Limiter   ratelimit.Store
+}
+
+// ConnContext must be the ConnContext of the http.Server serving the router, streaming handlers use
+// the connection to extend its write deadline
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return handlers.ConnContext(ctx, conn)
 }
 
 // SetUpRouter godoc
+// @description Errors are returned inside the ResponseModel envelope by default.
+// @description Send "Accept: application/problem+json" (or set ERROR_FORMAT=problem) to receive RFC 7807 models.ProblemDetails documents instead.
+// @description Requests are rate limited per user, api key or client ip; responses carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers and a 429 with Retry-After once the limit is exceeded.
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
 func New(opt *RouterOptions) *gin.Engine {
+	handlerV1 := handlers.NewHandler(&handlers.HandlerOptions{
+		Log:       opt.Log,
+		Cfg:       opt.Cfg,
+		Services:  opt.Services,
+		Policy:    opt.Policy,
+		Storage:   opt.Storage,
+		OTPSender: opt.OTPSender,
+		Jobs:      opt.Jobs,
+		Limiter:   opt.Limiter,
+	})
+
 	router := gin.New()
-	router.Use(gin.Logger())
+	// forwarding headers are only believed from TRUSTED_PROXIES, so clients can't pick the ip they are rate limited and logged as
+	if err := router.SetTrustedProxies(opt.Cfg.TrustedProxies); err != nil {
+		opt.Log.Fatal("invalid trusted proxies", logger.Error(err))
+	}
+	router.Use(handlerV1.RequestID(), handlerV1.RequestLogger())
 	router.Use(gin.Recovery())
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
@@ -37,19 +73,31 @@ func New(opt *RouterOptions) *gin.Engine {
 
 	router.Use(cors.New(config))
 
-	handlerV1 := handlers.NewHandler(&handlers.HandlerOptions{
-		Log:      opt.Log,
-		Cfg:      opt.Cfg,
-		Services: opt.Services,
-	})
+	router.GET("/health/live", handlerV1.HealthLive)
+	router.GET("/health/ready", handlerV1.HealthReady)
+
+	// every request is limited per client ip before any credentials are checked
+	apiV1 := router.Group("/v1", handlerV1.IPRateLimit())
+	rateLimit := handlerV1.RateLimit()
+
+	//auth, limited per client ip as the caller is not known yet
+	auth := apiV1.Group("", rateLimit)
+	auth.POST("/auth/login", handlerV1.Login)
+	auth.POST("/auth/refresh", handlerV1.RefreshToken)
+	auth.POST("/auth/logout", handlerV1.Logout)
+	auth.POST("/otp/send", handlerV1.SendOTP)
+	auth.POST("/otp/verify", handlerV1.VerifyOTP)
+
+	apiV1.Use(handlerV1.AuthMiddleware(), rateLimit, handlerV1.Authorize(routePolicies))
 
-	apiV1 := router.Group("/v1")
 	//book_category
 	apiV1.POST("/book_category", handlerV1.CreateBookCategory)
 	apiV1.GET("/book_category", handlerV1.GetAllBookCategory)
 	apiV1.GET("/book_category/:book_category_id", handlerV1.GetBookCategory)
 	apiV1.PUT("/book_category", handlerV1.UpdateBookCategory)
 	apiV1.DELETE("/book_category/:book_category_id", handlerV1.DeleteBookCategory)
+	apiV1.POST("/book_category/import", handlerV1.ImportBookCategory)
+	apiV1.GET("/book_category/export", handlerV1.ExportBookCategory)
 
 	//book
 	apiV1.POST("/book", handlerV1.CreateBook)
@@ -57,6 +105,26 @@ func New(opt *RouterOptions) *gin.Engine {
 	apiV1.GET("/book/:book_id", handlerV1.GetBook)
 	apiV1.PUT("/book", handlerV1.UpdateBook)
 	apiV1.DELETE("/book/:book_id", handlerV1.DeleteBook)
+	apiV1.POST("/book/batch", handlerV1.CreateBookBatch)
+	apiV1.PUT("/book/batch", handlerV1.UpdateBookBatch)
+	apiV1.DELETE("/book/batch", handlerV1.DeleteBookBatch)
+	apiV1.POST("/book/import", handlerV1.ImportBook)
+	apiV1.GET("/book/export", handlerV1.ExportBook)
+
+	//jobs
+	apiV1.GET("/jobs/:job_id", handlerV1.GetJob)
+	apiV1.GET("/jobs/:job_id/result", handlerV1.GetJobResult)
+	apiV1.DELETE("/jobs/:job_id", handlerV1.CancelJob)
+
+	//api_keys
+	apiV1.POST("/api_keys", handlerV1.CreateApiKey)
+	apiV1.GET("/api_keys", handlerV1.GetAllApiKeys)
+	apiV1.GET("/api_keys/:api_key_id", handlerV1.GetApiKey)
+	apiV1.DELETE("/api_keys/:api_key_id", handlerV1.DeleteApiKey)
+
+	//admin
+	apiV1.GET("/admin/metrics", handlerV1.GetMetrics)
+	apiV1.GET("/admin/upstreams", handlerV1.GetUpstreamBackends)
 
 	url := ginSwagger.URL("swagger/doc.json") // The url pointing to API definition
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
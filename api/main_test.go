@@ -0,0 +1,71 @@
+package api
+
+import (
+	"book-api-gateway/config"
+	"book-api-gateway/pkg/logger"
+	"book-api-gateway/pkg/ratelimit"
+	"book-api-gateway/pkg/rbac"
+	"book-api-gateway/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIPRateLimitClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		want           []int
+	}{
+		{
+			name: "forwarding headers from clients are ignored",
+			want: []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests},
+		},
+		{
+			name:           "forwarding headers from trusted proxies are believed",
+			trustedProxies: []string{"203.0.113.0/24"},
+			want:           []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strg, err := memory.NewStorage("", nil)
+			if err != nil {
+				t.Fatalf("NewStorage() error = %v", err)
+			}
+
+			router := New(&RouterOptions{
+				Log: logger.New(logger.LevelFatal, "test"),
+				Cfg: config.Config{
+					SecretKey:      "test-secret-key",
+					RateLimitStore: ratelimit.StoreMemory,
+					RateLimitIP:    "2/1m",
+					TrustedProxies: tt.trustedProxies,
+				},
+				Policy:  rbac.DefaultPolicy(),
+				Storage: strg,
+				Limiter: ratelimit.NewMemoryStore(),
+			})
+
+			for i, want := range tt.want {
+				req := httptest.NewRequest(http.MethodGet, "/v1/book", nil)
+				req.RemoteAddr = "203.0.113.7:40000"
+				req.Header.Set("X-Forwarded-For", "198.51.100."+strconv.Itoa(i+1))
+				req.Header.Set("X-Real-IP", "198.51.100."+strconv.Itoa(i+1))
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				if rec.Code != want {
+					t.Fatalf("request %d status = %d, want %d", i+1, rec.Code, want)
+				}
+			}
+		})
+	}
+}
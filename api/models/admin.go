@@ -0,0 +1,14 @@
+package models
+
+type UpstreamBackend struct {
+	Upstream string `json:"upstream"`
+	Target   string `json:"target"`
+	Address  string `json:"address"`
+	State    string `json:"state"`
+	Error    string `json:"error,omitempty"`
+}
+
+type GetAllUpstreamBackendResponse struct {
+	BackendList []UpstreamBackend `json:"backend_list"`
+	Count       int32             `json:"count"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type ApiKeyScope struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+type ApiKey struct {
+	Id        string        `json:"id"`
+	Name      string        `json:"name"`
+	Scopes    []ApiKeyScope `json:"scopes"`
+	Role      string        `json:"role"`
+	CreatedBy string        `json:"created_by"`
+	ExpiresAt *time.Time    `json:"expires_at"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type CreateApiKey struct {
+	Name      string        `json:"name"`
+	Scopes    []ApiKeyScope `json:"scopes"`
+	ExpiresAt *time.Time    `json:"expires_at"`
+}
+
+type CreateApiKeyResponse struct {
+	ApiKey
+	Key string `json:"key"`
+}
+
+type GetAllApiKeyResponse struct {
+	ApiKeyList []ApiKey `json:"api_key_list"`
+	Count      int32    `json:"count"`
+}
@@ -0,0 +1,26 @@
+package models
+
+type LoginRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type SendOTPRequest struct {
+	Recipient string `json:"recipient"`
+}
+
+type VerifyOTPRequest struct {
+	Recipient string `json:"recipient"`
+	Code      string `json:"code"`
+}
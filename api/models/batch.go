@@ -0,0 +1,17 @@
+package models
+
+// BatchItemResult is the outcome of a single item of a batch request
+type BatchItemResult struct {
+	// Index is the position of the item in the request array
+	Index  int         `json:"index"`
+	Id     string      `json:"id,omitempty"`
+	Status int         `json:"status"`
+	Error  *ErrorModel `json:"error,omitempty"`
+}
+
+// BatchResponse reports every item of a batch request, which may partially succeed
+type BatchResponse struct {
+	Results   []BatchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
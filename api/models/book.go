@@ -1,11 +1,20 @@
 package models
 
+import (
+	"context"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
 type Book struct {
 	Id          string `json:"id"`
 	Name        string `json:"name"`
 	Category_id string `json:"category_id"`
 	Created_at  string `json:"created_at"`
 	Updated_at  string `json:"updated_at"`
+	// Category is only set when requested with ?expand=category
+	Category *BookCategory `json:"category,omitempty"`
 }
 type CreateBook struct {
 	Name        string `json:"name"`
@@ -13,16 +22,59 @@ type CreateBook struct {
 }
 
 type GetAllBookResponse struct {
-	BookList []Book `json:"book_list"`
-	Count    int32  `json:"count"`
+	BookList   []Book `json:"book_list"`
+	Count      int32  `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
-type GetBookResponse struct {
-	Id       string `json:"id"`
-	Name     string `json:"name"`
-	Category string `json:"category"`
+
+// GetAllBookQuery is the filters and sort order accepted by GET /v1/book
+type GetAllBookQuery struct {
+	Name        string   `form:"name" json:"name"`
+	Sort        string   `form:"sort" json:"sort"`
+	CategoryId  string   `form:"category_id" json:"category_id"`
+	Ids         []string `form:"ids" json:"ids"`
+	CreatedFrom string   `form:"created_from" json:"created_from"`
+	CreatedTo   string   `form:"created_to" json:"created_to"`
+	UpdatedFrom string   `form:"updated_from" json:"updated_from"`
+	UpdatedTo   string   `form:"updated_to" json:"updated_to"`
 }
+
+// BookSortFields are the accepted values of GetAllBookQuery.Sort
+var BookSortFields = []interface{}{"name", "-name", "created_at", "-created_at", "updated_at", "-updated_at"}
+
+// MaxBookIds bounds the number of ids a single listing may be filtered by
+const MaxBookIds = 100
+
 type UpdateBook struct {
 	Id          string `json:"id"`
 	Name        string `json:"name"`
 	Category_id string `json:"category_id"`
 }
+
+func (b CreateBook) ValidateWithContext(ctx context.Context) error {
+	return validation.ValidateStructWithContext(ctx, &b,
+		validation.Field(&b.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&b.Category_id, validation.Required, isUUID),
+	)
+}
+
+func (b UpdateBook) ValidateWithContext(ctx context.Context) error {
+	return validation.ValidateStructWithContext(ctx, &b,
+		validation.Field(&b.Id, validation.Required, isUUID),
+		validation.Field(&b.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&b.Category_id, validation.Required, isUUID),
+	)
+}
+
+func (q GetAllBookQuery) ValidateWithContext(ctx context.Context) error {
+	return validation.ValidateStructWithContext(ctx, &q,
+		validation.Field(&q.Sort, validation.In(BookSortFields...)),
+		validation.Field(&q.CategoryId, isUUID),
+		validation.Field(&q.Ids, validation.Length(0, MaxBookIds), validation.Each(validation.Required, isUUID)),
+		validation.Field(&q.CreatedFrom, validation.Date(time.RFC3339)),
+		validation.Field(&q.CreatedTo, validation.Date(time.RFC3339), validation.By(notBefore(q.CreatedFrom))),
+		validation.Field(&q.UpdatedFrom, validation.Date(time.RFC3339)),
+		validation.Field(&q.UpdatedTo, validation.Date(time.RFC3339), validation.By(notBefore(q.UpdatedFrom))),
+	)
+}
@@ -1,5 +1,11 @@
 package models
 
+import (
+	"context"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
 type BookCategory struct {
 	Id         string `json:"id"`
 	Name       string `json:"name"`
@@ -14,11 +20,27 @@ type CreateBookCategory struct {
 type GetAllBookCategoryResponse struct {
 	BookCategoryList []BookCategory `json:"book_category_list"`
 	Count            int32          `json:"count"`
+	NextCursor       string         `json:"next_cursor,omitempty"`
+	PrevCursor       string         `json:"prev_cursor,omitempty"`
 }
 type UpdateBookCategory struct {
 	Id   string `json:"id"`
 	Name string `json:"name"`
 }
+
+func (b CreateBookCategory) Validate() error {
+	return validation.ValidateStruct(&b,
+		validation.Field(&b.Name, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+func (b UpdateBookCategory) ValidateWithContext(ctx context.Context) error {
+	return validation.ValidateStructWithContext(ctx, &b,
+		validation.Field(&b.Id, validation.Required, isUUID),
+		validation.Field(&b.Name, validation.Required, validation.Length(1, 255)),
+	)
+}
+
 type MsgModel struct {
 	Msg string `json:"msg"`
 }
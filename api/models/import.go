@@ -0,0 +1,43 @@
+package models
+
+import (
+	"context"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// ImportBookRow is a row of a book import. The category is given either by id or by name.
+type ImportBookRow struct {
+	Name        string `json:"name"`
+	Category_id string `json:"category_id"`
+	Category    string `json:"category"`
+}
+
+// ImportReport summarizes an import. Rows are imported independently, so an import may partially succeed.
+type ImportReport struct {
+	DryRun   bool `json:"dry_run"`
+	Rows     int  `json:"rows"`
+	Imported int  `json:"imported"`
+	Failed   int  `json:"failed"`
+	// CreatedCategories are the names of categories created, or to be created on a dry run, for book rows
+	CreatedCategories []string `json:"created_categories,omitempty"`
+	// Errors lists the first failed rows, see ErrorsTruncated
+	Errors          []ImportRowError `json:"errors,omitempty"`
+	ErrorsTruncated bool             `json:"errors_truncated,omitempty"`
+	// Error is set when the file could not be read to the end
+	Error *ErrorModel `json:"error,omitempty"`
+}
+
+// ImportRowError is the reason a single row was not imported
+type ImportRowError struct {
+	Line  int        `json:"line"`
+	Error ErrorModel `json:"error"`
+}
+
+func (r ImportBookRow) ValidateWithContext(ctx context.Context) error {
+	return validation.ValidateStructWithContext(ctx, &r,
+		validation.Field(&r.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&r.Category_id, validation.When(r.Category == "", validation.Required), isUUID),
+		validation.Field(&r.Category, validation.Length(1, 255)),
+	)
+}
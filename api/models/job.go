@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is the state of a background import or export
+type Job struct {
+	Id     string `json:"id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status" enums:"queued,running,succeeded,failed,canceled"`
+	// Done counts the rows processed so far, out of Total when it is known
+	Done  int    `json:"done"`
+	Total int    `json:"total,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Summary describes the outcome, an ImportReport for imports
+	Summary json.RawMessage `json:"summary,omitempty" swaggertype:"object"`
+	// ResultUrl is where the result of a succeeded export can be downloaded
+	ResultUrl  string     `json:"result_url,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
@@ -7,3 +7,30 @@ type ResponseModel struct {
 	Error   interface{} `json:"error"`
 	Data    interface{} `json:"data"`
 }
+
+// ErrorModel is the error envelope carried in ResponseModel.Error
+type ErrorModel struct {
+	Code    string                   `json:"code"`
+	Message string                   `json:"message"`
+	Fields  []FieldError             `json:"fields,omitempty"`
+	Details []map[string]interface{} `json:"details,omitempty"`
+}
+
+// FieldError points at one invalid input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ProblemDetails is an RFC 7807 application/problem+json error document
+type ProblemDetails struct {
+	Type     string                   `json:"type"`
+	Title    string                   `json:"title"`
+	Status   int                      `json:"status"`
+	Detail   string                   `json:"detail,omitempty"`
+	Instance string                   `json:"instance,omitempty"`
+	Code     string                   `json:"code"`
+	TraceId  string                   `json:"trace_id,omitempty"`
+	Errors   []FieldError             `json:"errors,omitempty"`
+	Details  []map[string]interface{} `json:"details,omitempty"`
+}
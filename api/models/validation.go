@@ -0,0 +1,69 @@
+package models
+
+import (
+	"book-api-gateway/pkg/uuid"
+	"context"
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// uuidVersionsKey is the context key holding the UUID versions set by WithUUIDVersions
+type uuidVersionsKey struct{}
+
+// WithUUIDVersions returns a copy of ctx under which ParseUUID and the validation rules of the models
+// only accept UUIDs of one of versions, any when empty
+func WithUUIDVersions(ctx context.Context, versions []int) context.Context {
+	return context.WithValue(ctx, uuidVersionsKey{}, versions)
+}
+
+// ParseUUID parses s as a UUID of one of the versions of ctx. Path params, query params and bodies are all checked with it.
+func ParseUUID(ctx context.Context, s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	versions, _ := ctx.Value(uuidVersionsKey{}).([]int)
+	return id, id.Check(versions...)
+}
+
+// isUUID accepts empty values (combine with validation.Required) and UUIDs accepted by ParseUUID.
+// Models using it implement validation.ValidatableWithContext so the versions reach it.
+var isUUID = validation.WithContext(func(ctx context.Context, value interface{}) error {
+	s, _ := value.(string)
+	if s == "" {
+		return nil
+	}
+
+	if _, err := ParseUUID(ctx, s); err != nil {
+		return validation.NewError("validation_is_uuid", "must be a valid UUID: "+err.Error())
+	}
+	return nil
+})
+
+// notBefore rejects RFC 3339 values earlier than from, for the upper bound of a time range.
+// Unparsable values are left to validation.Date.
+func notBefore(from string) validation.RuleFunc {
+	return func(value interface{}) error {
+		to, _ := value.(string)
+		if from == "" || to == "" {
+			return nil
+		}
+
+		fromTime, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil
+		}
+		toTime, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil
+		}
+
+		if toTime.Before(fromTime) {
+			return errors.New("must not be before " + from)
+		}
+		return nil
+	}
+}
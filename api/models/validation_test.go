@@ -0,0 +1,46 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	testUUIDv4 = "9b2f1c3e-5d4a-4f6b-8c7d-0e1f2a3b4c5d"
+	testUUIDv7 = "01890a5d-ac96-774b-bcce-b302099a8057"
+)
+
+func TestUUIDVersionsFollowTheContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []int
+		id       string
+		wantErr  bool
+	}{
+		{name: "any version when unset", id: testUUIDv7},
+		{name: "allowed version", versions: []int{4}, id: testUUIDv4},
+		{name: "other version", versions: []int{4}, id: testUUIDv7, wantErr: true},
+		{name: "one of several versions", versions: []int{4, 7}, id: testUUIDv7},
+		{name: "not a uuid", id: "not-a-uuid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.versions != nil {
+				ctx = WithUUIDVersions(ctx, tt.versions)
+			}
+
+			if _, err := ParseUUID(ctx, tt.id); (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUUID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			book := UpdateBook{Id: tt.id, Name: "book", Category_id: testUUIDv4}
+			if err := validation.ValidateWithContext(ctx, book); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateWithContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
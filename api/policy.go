@@ -0,0 +1,28 @@
+package api
+
+import "book-api-gateway/pkg/rbac"
+
+// routePolicies maps "METHOD /full/path" to the permission the route requires.
+// Routes missing from the table are open to any authenticated caller.
+var routePolicies = map[string]string{
+	"POST /v1/book_category":                     rbac.PermissionBookCategoryWrite,
+	"PUT /v1/book_category":                      rbac.PermissionBookCategoryWrite,
+	"DELETE /v1/book_category/:book_category_id": rbac.PermissionBookCategoryWrite,
+	"POST /v1/book_category/import":              rbac.PermissionBookCategoryWrite,
+
+	"POST /v1/book":            rbac.PermissionBookWrite,
+	"PUT /v1/book":             rbac.PermissionBookWrite,
+	"DELETE /v1/book/:book_id": rbac.PermissionBookWrite,
+	"POST /v1/book/batch":      rbac.PermissionBookWrite,
+	"PUT /v1/book/batch":       rbac.PermissionBookWrite,
+	"DELETE /v1/book/batch":    rbac.PermissionBookWrite,
+	"POST /v1/book/import":     rbac.PermissionBookWrite,
+
+	"POST /v1/api_keys":               rbac.PermissionApiKeyManage,
+	"GET /v1/api_keys":                rbac.PermissionApiKeyManage,
+	"GET /v1/api_keys/:api_key_id":    rbac.PermissionApiKeyManage,
+	"DELETE /v1/api_keys/:api_key_id": rbac.PermissionApiKeyManage,
+
+	"GET /v1/admin/metrics":   rbac.PermissionAdminRead,
+	"GET /v1/admin/upstreams": rbac.PermissionAdminRead,
+}
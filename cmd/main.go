@@ -3,21 +3,177 @@ package main
 import (
 	"book-api-gateway/api"
 	"book-api-gateway/config"
+	"book-api-gateway/pkg/certs"
+	"book-api-gateway/pkg/jobs"
 	"book-api-gateway/pkg/logger"
+	"book-api-gateway/pkg/otp"
+	"book-api-gateway/pkg/ratelimit"
+	"book-api-gateway/pkg/rbac"
 	"book-api-gateway/services"
+	"book-api-gateway/storage"
+	"book-api-gateway/storage/file"
+	"book-api-gateway/storage/memory"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/go-redis/redis/v8"
 )
 
 func main() {
 	cfg := config.Load()
 	log := logger.New(cfg.LogLevel, "example_api_gateway")
 
-	gprcClients, _ := services.NewServicesRepo(&cfg)
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("invalid config", logger.Error(err))
+	}
+
+	gprcClients, err := services.NewServicesRepo(&cfg, log)
+	if err != nil {
+		log.Fatal("error while connecting to grpc services", logger.Error(err))
+	}
+
+	policy, err := rbac.Load(cfg.RBACPolicyFile)
+	if err != nil {
+		log.Fatal("error while loading rbac policy", logger.Error(err))
+	}
+
+	var apiKeyRepo storage.ApiKeyRepoI
+	if cfg.ApiKeyStore == "file" {
+		apiKeyRepo, err = file.NewApiKeyRepo(cfg.ApiKeyDir)
+		if err != nil {
+			log.Fatal("error while initializing api key store", logger.Error(err))
+		}
+	}
+
+	strg, err := memory.NewStorage(cfg.UsersFile, apiKeyRepo)
+	if err != nil {
+		log.Fatal("error while initializing storage", logger.Error(err))
+	}
+
+	otpSender, err := otp.NewSender(cfg.OTPSender, otp.Options{
+		WebhookURL:     cfg.OTPWebhookURL,
+		WebhookTimeout: cfg.OTPWebhookTimeout,
+	}, log)
+	if err != nil {
+		log.Fatal("error while initializing otp sender", logger.Error(err))
+	}
+
+	jobRepo := memory.NewJobRepo()
+	if cfg.JobStore == "file" {
+		jobRepo, err = file.NewJobRepo(filepath.Join(cfg.JobDir, "state"))
+		if err != nil {
+			log.Fatal("error while initializing job store", logger.Error(err))
+		}
+	}
+
+	jobManager, err := jobs.NewManager(jobRepo, cfg.JobDir, cfg.JobWorkers, cfg.JobQueueSize, cfg.JobTTL, log)
+	if err != nil {
+		log.Fatal("error while initializing job manager", logger.Error(err))
+	}
+
+	limiter := ratelimit.NewMemoryStore()
+	var redisClient *redis.Client
+	if cfg.RateLimitStore == ratelimit.StoreRedis {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:        cfg.RedisAddr,
+			Password:    cfg.RedisPassword,
+			DB:          cfg.RedisDB,
+			DialTimeout: cfg.RedisDialTimeout,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.RedisDialTimeout)
+		err := redisClient.Ping(ctx).Err()
+		cancel()
+		if err != nil {
+			log.Fatal("error while connecting to redis", logger.Error(err))
+		}
+
+		limiter = ratelimit.NewRedisStore(redisClient, "ratelimit:")
+	}
 
-	server := api.New(&api.RouterOptions{
-		Log:      log,
-		Cfg:      cfg,
-		Services: gprcClients,
+	router := api.New(&api.RouterOptions{
+		Log:       log,
+		Cfg:       cfg,
+		Services:  gprcClients,
+		Policy:    policy,
+		Storage:   strg,
+		OTPSender: otpSender,
+		Jobs:      jobManager,
+		Limiter:   limiter,
 	})
 
-	server.Run(cfg.HttpPort)
+	server := &http.Server{
+		Addr:              cfg.HttpPort,
+		Handler:           router,
+		ReadTimeout:       cfg.HttpReadTimeout,
+		ReadHeaderTimeout: cfg.HttpReadHeaderTimeout,
+		WriteTimeout:      cfg.HttpWriteTimeout,
+		IdleTimeout:       cfg.HttpIdleTimeout,
+		MaxHeaderBytes:    cfg.HttpMaxHeaderBytes,
+		ConnContext:       api.ConnContext,
+	}
+
+	if cfg.HttpTLSCertFile != "" {
+		reloader, err := certs.NewReloader(cfg.HttpTLSCertFile, cfg.HttpTLSKeyFile, cfg.TLSReloadInterval, log)
+		if err != nil {
+			log.Fatal("error while loading http tls certificate", logger.Error(err))
+		}
+
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	}
+
+	go func() {
+		log.Info("http server started", logger.String("addr", cfg.HttpPort), logger.Bool("tls", server.TLSConfig != nil))
+
+		var err error
+		if server.TLSConfig != nil {
+			// the certificate comes from TLSConfig.GetCertificate
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("error while running http server", logger.Error(err))
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	log.Info("shutting down", logger.String("signal", sig.String()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// stop accepting connections and wait for in-flight requests before closing their upstream
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error("error while shutting down http server", logger.Error(err))
+	}
+
+	// jobs still need the upstream connections while they wind down
+	if err := jobManager.Shutdown(ctx); err != nil {
+		log.Error("error while shutting down jobs", logger.Error(err))
+	}
+
+	if err := gprcClients.Close(); err != nil {
+		log.Error("error while closing grpc connections", logger.Error(err))
+	}
+
+	if redisClient != nil {
+		if err := redisClient.Close(); err != nil {
+			log.Error("error while closing redis connection", logger.Error(err))
+		}
+	}
+
+	log.Info("server stopped")
+	_ = logger.Cleanup(log)
 }
@@ -1,22 +1,175 @@
 package config
 
 import (
+	"book-api-gateway/pkg/helper"
+	"book-api-gateway/pkg/lb"
+	"book-api-gateway/pkg/otp"
+	"book-api-gateway/pkg/ratelimit"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
+	"google.golang.org/grpc/codes"
 )
 
 // Config ...
 type Config struct {
 	Environment string // develop, staging, production
 
-	BookServiceHost string
-	BookServicePort int
+	Upstreams map[string]Upstream // keyed by the names in UpstreamNames
+
+	HealthCheckTimeout time.Duration
+
+	ApiKeyStore string // memory or file
+	ApiKeyDir   string
+
+	UUIDVersions []int // allowed versions of UUIDs in paths, queries and bodies, any when empty
+
+	DefaultPageSize int
+	MaxPageSize     int
+
+	ExpandConcurrency int // max upstream calls in flight while embedding relations into a listing
+
+	MaxBatchSize     int
+	BatchConcurrency int           // workers fanning a batch request or an import out to the upstream services
+	BatchTimeout     time.Duration // bounds a whole batch request, under HttpWriteTimeout, while each item gets the route's timeout
+
+	MaxImportSize     int64 // bytes
+	MaxSyncImportRows int   // larger imports need async=true
+
+	JobStore     string // memory or file
+	JobDir       string // job state of the file store, job results and the uploads of async imports
+	JobWorkers   int
+	JobQueueSize int
+	JobTTL       time.Duration // how long finished jobs and their results are kept
+
+	UpstreamTimeout time.Duration
+	RouteTimeouts   map[string]time.Duration // keyed by "METHOD /full/path"
+
+	RetryMaxAttempts       int // including the first call of an idempotent rpc, 1 disables retries
+	RetryInitialBackoff    time.Duration
+	RetryMaxBackoff        time.Duration
+	RetryBackoffMultiplier float64
+	RetryCodes             []string // grpc status codes such as UNAVAILABLE
+
+	BreakerFailureThreshold int // consecutive upstream failures which open a service's circuit breaker
+	BreakerOpenTimeout      time.Duration
+	BreakerHalfOpenRequests int
+
+	RateLimitStore   string            // memory or redis
+	RateLimitDefault string            // "<requests>/<period>" shared by routes without their own limit, unlimited when empty
+	RateLimits       map[string]string // keyed by "METHOD /full/path"
+	RateLimitIP      string            // "<requests>/<period>" per client ip over all of /v1, checked before authentication
+
+	TrustedProxies []string // ips or cidrs of the proxies whose X-Forwarded-For and X-Real-IP are believed, none when empty
+
+	RedisAddr        string
+	RedisPassword    string
+	RedisDB          int
+	RedisDialTimeout time.Duration
 
 	LogLevel string
 	HttpPort string
+
+	HttpTLSCertFile   string // plain http is only allowed in develop
+	HttpTLSKeyFile    string
+	TLSReloadInterval time.Duration // how often certificate files are checked for changes
+
+	ErrorFormat        string // envelope, problem
+	ProblemTypeBaseURL string
+
+	HttpReadTimeout       time.Duration
+	HttpReadHeaderTimeout time.Duration
+	HttpWriteTimeout      time.Duration
+	HttpIdleTimeout       time.Duration
+	HttpMaxHeaderBytes    int
+	ShutdownTimeout       time.Duration
+
+	SecretKey       string // signs tokens and pagination cursors, no default
+	RBACPolicyFile  string
+	UsersFile       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	OTPLength         int
+	OTPTTL            time.Duration
+	OTPMaxAttempts    int
+	OTPResendCooldown time.Duration // per recipient
+	OTPSender         string        // log in develop, no default elsewhere
+	OTPWebhookURL     string        // where the webhook sender posts codes
+	OTPWebhookTimeout time.Duration
+}
+
+const (
+	// UpstreamBook serves genproto.BookService
+	UpstreamBook = "book"
+	// UpstreamBookCategory serves genproto.BookCategoryService
+	UpstreamBookCategory = "book_category"
+)
+
+// UpstreamNames is the registry of upstream services. Each one is configured by the "<NAME>_SERVICE_*" env vars
+// and falls back to the settings of the first one, so services are served next to each other unless configured otherwise.
+var UpstreamNames = []string{UpstreamBook, UpstreamBookCategory}
+
+// leakedSecretKey was the default signing key and is public, tokens signed with it can be forged by anyone
+const leakedSecretKey = "FfLbN7pIEYe8@!EqrttOLiwa(H8)7Ddo"
+
+// minSecretKeyLength is the shortest SECRET_KEY accepted outside develop
+const minSecretKeyLength = 32
+
+// Upstream configures the connection to one upstream grpc service
+type Upstream struct {
+	Host  string
+	Port  int
+	Addrs []string // "host:port" replicas, or a single "dns:///name:port"; overrides host and port
+
+	LBPolicy    string // round_robin or least_request
+	HealthName  string
+	HealthCheck bool // grpc.health.v1 checks on every replica, unhealthy ones get no calls
+
+	TLS           bool // plaintext is only allowed in develop
+	TLSCAFile     string
+	TLSCertFile   string // client certificate for mTLS
+	TLSKeyFile    string
+	TLSServerName string // overrides the name verified against the server certificate
+
+	DialBlock   bool
+	DialTimeout time.Duration
+	Timeout     time.Duration // caps every call on top of the route timeouts, no cap when zero
+
+	KeepaliveTime    time.Duration // ping after this long without activity, disabled when zero
+	KeepaliveTimeout time.Duration
+
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+}
+
+// Validate ...
+func (u Upstream) Validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.Host, validation.When(len(u.Addrs) == 0, validation.Required, is.Host)),
+		validation.Field(&u.Port, validation.When(len(u.Addrs) == 0, validation.Required, validation.By(validatePortNumber))),
+		validation.Field(&u.Addrs, validation.By(validateServiceAddrs)),
+		validation.Field(&u.LBPolicy, validation.Required, validation.In(lb.RoundRobin, lb.LeastRequest)),
+		validation.Field(&u.TLSCertFile, validation.When(u.TLSKeyFile != "", validation.Required)),
+		validation.Field(&u.TLSKeyFile, validation.When(u.TLSCertFile != "", validation.Required)),
+		validation.Field(&u.TLSServerName, is.Host),
+		validation.Field(&u.DialTimeout, validation.Required),
+		validation.Field(&u.Timeout, validation.Min(time.Duration(0))),
+		validation.Field(&u.KeepaliveTime, validation.Min(time.Duration(0))),
+		validation.Field(&u.KeepaliveTimeout, validation.When(u.KeepaliveTime > 0, validation.Required)),
+		validation.Field(&u.MaxRecvMsgSize, validation.Required, validation.Min(1)),
+		validation.Field(&u.MaxSendMsgSize, validation.Required, validation.Min(1)),
+	)
 }
 
 // Load loads environment vars and inflates Config
@@ -32,12 +185,378 @@ func Load() Config {
 	config.LogLevel = cast.ToString(getOrReturnDefault("LOG_LEVEL", "debug"))
 	config.HttpPort = cast.ToString(getOrReturnDefault("HTTP_PORT", "your_port"))
 
-	config.BookServiceHost = cast.ToString(getOrReturnDefault("BOOK_SERVICE_HOST", "localhost"))
-	config.BookServicePort = cast.ToInt(getOrReturnDefault("BOOK_SERVICE_PORT", "your_service_port"))
+	config.HttpTLSCertFile = cast.ToString(getOrReturnDefault("HTTP_TLS_CERT_FILE", ""))
+	config.HttpTLSKeyFile = cast.ToString(getOrReturnDefault("HTTP_TLS_KEY_FILE", ""))
+	config.TLSReloadInterval = cast.ToDuration(getOrReturnDefault("TLS_RELOAD_INTERVAL", "10s"))
+
+	config.ErrorFormat = cast.ToString(getOrReturnDefault("ERROR_FORMAT", "envelope"))
+	config.ProblemTypeBaseURL = cast.ToString(getOrReturnDefault("PROBLEM_TYPE_BASE_URL", ""))
+
+	config.HttpReadTimeout = cast.ToDuration(getOrReturnDefault("HTTP_READ_TIMEOUT", "15s"))
+	config.HttpReadHeaderTimeout = cast.ToDuration(getOrReturnDefault("HTTP_READ_HEADER_TIMEOUT", "5s"))
+	config.HttpWriteTimeout = cast.ToDuration(getOrReturnDefault("HTTP_WRITE_TIMEOUT", "30s"))
+	config.HttpIdleTimeout = cast.ToDuration(getOrReturnDefault("HTTP_IDLE_TIMEOUT", "60s"))
+	config.HttpMaxHeaderBytes = cast.ToInt(getOrReturnDefault("HTTP_MAX_HEADER_BYTES", 1<<20))
+	config.ShutdownTimeout = cast.ToDuration(getOrReturnDefault("SHUTDOWN_TIMEOUT", "15s"))
+
+	upstreamDefaults := Upstream{
+		Host:           "localhost",
+		LBPolicy:       lb.RoundRobin,
+		HealthCheck:    true,
+		DialBlock:      cast.ToBool(getOrReturnDefault("GRPC_DIAL_BLOCK", false)),
+		DialTimeout:    cast.ToDuration(getOrReturnDefault("GRPC_DIAL_TIMEOUT", "5s")),
+		MaxRecvMsgSize: 4 << 20,
+		MaxSendMsgSize: 4 << 20,
+	}
+	config.Upstreams = make(map[string]Upstream, len(UpstreamNames))
+	for i, name := range UpstreamNames {
+		u := loadUpstream(strings.ToUpper(name)+"_SERVICE", upstreamDefaults)
+		if i == 0 {
+			upstreamDefaults = u
+		}
+		config.Upstreams[name] = u
+	}
+
+	config.HealthCheckTimeout = cast.ToDuration(getOrReturnDefault("HEALTH_CHECK_TIMEOUT", "2s"))
+
+	config.UUIDVersions = parseIntList(cast.ToString(getOrReturnDefault("UUID_VERSIONS", "")))
+
+	config.DefaultPageSize = cast.ToInt(getOrReturnDefault("DEFAULT_PAGE_SIZE", 100))
+	config.MaxPageSize = cast.ToInt(getOrReturnDefault("MAX_PAGE_SIZE", 500))
+
+	config.ExpandConcurrency = cast.ToInt(getOrReturnDefault("EXPAND_CONCURRENCY", 8))
+
+	config.MaxBatchSize = cast.ToInt(getOrReturnDefault("MAX_BATCH_SIZE", 1000))
+	config.BatchConcurrency = cast.ToInt(getOrReturnDefault("BATCH_CONCURRENCY", 8))
+	config.BatchTimeout = cast.ToDuration(getOrReturnDefault("BATCH_TIMEOUT", "25s"))
+
+	config.MaxImportSize = cast.ToInt64(getOrReturnDefault("MAX_IMPORT_SIZE", 64<<20))
+	config.MaxSyncImportRows = cast.ToInt(getOrReturnDefault("MAX_SYNC_IMPORT_ROWS", 1000))
+
+	config.ApiKeyStore = cast.ToString(getOrReturnDefault("API_KEY_STORE", "memory"))
+	config.ApiKeyDir = cast.ToString(getOrReturnDefault("API_KEY_DIR", "./api_keys"))
+
+	config.JobStore = cast.ToString(getOrReturnDefault("JOB_STORE", "memory"))
+	config.JobDir = cast.ToString(getOrReturnDefault("JOB_DIR", "./jobs"))
+	config.JobWorkers = cast.ToInt(getOrReturnDefault("JOB_WORKERS", 2))
+	config.JobQueueSize = cast.ToInt(getOrReturnDefault("JOB_QUEUE_SIZE", 100))
+	config.JobTTL = cast.ToDuration(getOrReturnDefault("JOB_TTL", "24h"))
+
+	config.UpstreamTimeout = cast.ToDuration(getOrReturnDefault("UPSTREAM_TIMEOUT", "5s"))
+	config.RouteTimeouts = parseRouteDurations(cast.ToString(getOrReturnDefault("ROUTE_TIMEOUTS", "")))
+
+	config.RetryMaxAttempts = cast.ToInt(getOrReturnDefault("RETRY_MAX_ATTEMPTS", 3))
+	config.RetryInitialBackoff = cast.ToDuration(getOrReturnDefault("RETRY_INITIAL_BACKOFF", "100ms"))
+	config.RetryMaxBackoff = cast.ToDuration(getOrReturnDefault("RETRY_MAX_BACKOFF", "1s"))
+	config.RetryBackoffMultiplier = cast.ToFloat64(getOrReturnDefault("RETRY_BACKOFF_MULTIPLIER", 2))
+	config.RetryCodes = parseStringList(cast.ToString(getOrReturnDefault("RETRY_CODES", "UNAVAILABLE,RESOURCE_EXHAUSTED,ABORTED")))
+
+	config.BreakerFailureThreshold = cast.ToInt(getOrReturnDefault("BREAKER_FAILURE_THRESHOLD", 5))
+	config.BreakerOpenTimeout = cast.ToDuration(getOrReturnDefault("BREAKER_OPEN_TIMEOUT", "30s"))
+	config.BreakerHalfOpenRequests = cast.ToInt(getOrReturnDefault("BREAKER_HALF_OPEN_REQUESTS", 1))
+
+	config.RateLimitStore = cast.ToString(getOrReturnDefault("RATE_LIMIT_STORE", "memory"))
+	config.RateLimitDefault = cast.ToString(getOrReturnDefault("RATE_LIMIT_DEFAULT", "600/1m"))
+	config.RateLimits = parseRouteValues(cast.ToString(getOrReturnDefault("RATE_LIMITS", "POST /v1/auth/login=10/1m,POST /v1/otp/send=5/1m")))
+	config.RateLimitIP = cast.ToString(getOrReturnDefault("RATE_LIMIT_IP", "1200/1m"))
+
+	config.TrustedProxies = parseStringList(cast.ToString(getOrReturnDefault("TRUSTED_PROXIES", "")))
+
+	config.RedisAddr = cast.ToString(getOrReturnDefault("REDIS_ADDR", "localhost:6379"))
+	config.RedisPassword = cast.ToString(getOrReturnDefault("REDIS_PASSWORD", ""))
+	config.RedisDB = cast.ToInt(getOrReturnDefault("REDIS_DB", 0))
+	config.RedisDialTimeout = cast.ToDuration(getOrReturnDefault("REDIS_DIAL_TIMEOUT", "5s"))
+
+	config.SecretKey = cast.ToString(getOrReturnDefault("SECRET_KEY", ""))
+	config.RBACPolicyFile = cast.ToString(getOrReturnDefault("RBAC_POLICY_FILE", ""))
+	config.UsersFile = cast.ToString(getOrReturnDefault("USERS_FILE", ""))
+	config.AccessTokenTTL = cast.ToDuration(getOrReturnDefault("ACCESS_TOKEN_TTL", "15m"))
+	config.RefreshTokenTTL = cast.ToDuration(getOrReturnDefault("REFRESH_TOKEN_TTL", "168h"))
+
+	config.OTPLength = cast.ToInt(getOrReturnDefault("OTP_LENGTH", 6))
+	config.OTPTTL = cast.ToDuration(getOrReturnDefault("OTP_TTL", "5m"))
+	config.OTPMaxAttempts = cast.ToInt(getOrReturnDefault("OTP_MAX_ATTEMPTS", 5))
+	config.OTPResendCooldown = cast.ToDuration(getOrReturnDefault("OTP_RESEND_COOLDOWN", "1m"))
+	// codes are only logged by default in develop, other environments must name a sender which delivers them
+	defaultOTPSender := ""
+	if config.Environment == "develop" {
+		defaultOTPSender = otp.SenderLog
+	}
+	config.OTPSender = cast.ToString(getOrReturnDefault("OTP_SENDER", defaultOTPSender))
+	config.OTPWebhookURL = cast.ToString(getOrReturnDefault("OTP_WEBHOOK_URL", ""))
+	config.OTPWebhookTimeout = cast.ToDuration(getOrReturnDefault("OTP_WEBHOOK_TIMEOUT", "5s"))
 
 	return config
 }
 
+// Validate reports the first misconfigured fields so the gateway can refuse to start
+func (c *Config) Validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.Environment, validation.Required, validation.In("develop", "staging", "production")),
+		validation.Field(&c.LogLevel, validation.In("debug", "info", "warn", "error")),
+		validation.Field(&c.HttpPort, validation.Required, validation.By(validateListenAddr)),
+		validation.Field(&c.HttpTLSCertFile,
+			validation.When(c.Environment != "develop", validation.Required.Error("is required outside develop")),
+			validation.When(c.HttpTLSKeyFile != "", validation.Required),
+		),
+		validation.Field(&c.HttpTLSKeyFile, validation.When(c.HttpTLSCertFile != "", validation.Required)),
+		validation.Field(&c.TLSReloadInterval, validation.Required),
+		validation.Field(&c.ErrorFormat, validation.In("envelope", "problem")),
+		validation.Field(&c.ProblemTypeBaseURL, is.URL),
+		validation.Field(&c.Upstreams, validation.Required, validation.By(c.validateUpstreamTLS)),
+		validation.Field(&c.SecretKey,
+			validation.Required,
+			validation.NotIn(leakedSecretKey).Error("must not be the key once committed to the repository"),
+			validation.When(c.Environment != "develop", validation.By(validateSecretKeyLength)),
+		),
+		validation.Field(&c.AccessTokenTTL, validation.Required, validation.Min(time.Second)),
+		validation.Field(&c.RefreshTokenTTL, validation.Required, validation.Min(c.AccessTokenTTL)),
+		validation.Field(&c.OTPLength, validation.Required, validation.Min(4), validation.Max(10)),
+		validation.Field(&c.OTPTTL, validation.Required, validation.Min(time.Second)),
+		validation.Field(&c.OTPMaxAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&c.OTPResendCooldown, validation.Required),
+		validation.Field(&c.OTPSender,
+			validation.Required,
+			validation.In(otp.SenderLog, otp.SenderWebhook),
+			validation.When(c.Environment != "develop", validation.NotIn(otp.SenderLog).Error("must deliver codes outside develop")),
+		),
+		validation.Field(&c.OTPWebhookURL, validation.When(c.OTPSender == otp.SenderWebhook, validation.Required, is.URL)),
+		validation.Field(&c.OTPWebhookTimeout, validation.When(c.OTPSender == otp.SenderWebhook, validation.Required)),
+		validation.Field(&c.ShutdownTimeout, validation.Required),
+		validation.Field(&c.HealthCheckTimeout, validation.Required),
+		validation.Field(&c.UUIDVersions, validation.Each(validation.Min(1), validation.Max(8))),
+		validation.Field(&c.MaxPageSize, validation.Required, validation.Min(1)),
+		validation.Field(&c.DefaultPageSize, validation.Required, validation.Min(1), validation.Max(c.MaxPageSize)),
+		validation.Field(&c.ExpandConcurrency, validation.Required, validation.Min(1)),
+		validation.Field(&c.MaxBatchSize, validation.Required, validation.Min(1)),
+		validation.Field(&c.BatchConcurrency, validation.Required, validation.Min(1)),
+		validation.Field(&c.BatchTimeout, validation.Required, validation.Max(c.HttpWriteTimeout).Exclusive().Error("must be shorter than HTTP_WRITE_TIMEOUT")),
+		validation.Field(&c.MaxImportSize, validation.Required, validation.Min(int64(1))),
+		validation.Field(&c.MaxSyncImportRows, validation.Required, validation.Min(1)),
+		validation.Field(&c.ApiKeyStore, validation.Required, validation.In("memory", "file")),
+		validation.Field(&c.ApiKeyDir, validation.When(c.ApiKeyStore == "file", validation.Required)),
+		validation.Field(&c.JobStore, validation.Required, validation.In("memory", "file")),
+		validation.Field(&c.JobDir, validation.Required),
+		validation.Field(&c.JobWorkers, validation.Required, validation.Min(1)),
+		validation.Field(&c.JobQueueSize, validation.Min(0)),
+		validation.Field(&c.JobTTL, validation.Required, validation.Min(time.Second)),
+		validation.Field(&c.UpstreamTimeout, validation.Required),
+		validation.Field(&c.RouteTimeouts, validation.Each(validation.Required)),
+		validation.Field(&c.RetryMaxAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&c.RetryInitialBackoff, validation.Required),
+		validation.Field(&c.RetryMaxBackoff, validation.Required, validation.Min(c.RetryInitialBackoff)),
+		validation.Field(&c.RetryBackoffMultiplier, validation.Required, validation.Min(1.0)),
+		validation.Field(&c.RetryCodes, validation.Each(validation.By(validateGrpcCode))),
+		validation.Field(&c.BreakerFailureThreshold, validation.Required, validation.Min(1)),
+		validation.Field(&c.BreakerOpenTimeout, validation.Required),
+		validation.Field(&c.BreakerHalfOpenRequests, validation.Required, validation.Min(1)),
+		validation.Field(&c.RateLimitStore, validation.Required, validation.In(ratelimit.StoreMemory, ratelimit.StoreRedis)),
+		validation.Field(&c.RateLimitDefault, validation.By(validateRateLimit)),
+		validation.Field(&c.RateLimits, validation.Each(validation.Required, validation.By(validateRateLimit))),
+		validation.Field(&c.RateLimitIP, validation.By(validateRateLimit)),
+		validation.Field(&c.TrustedProxies, validation.Each(validation.By(validateIPOrCIDR))),
+		validation.Field(&c.RedisAddr, validation.When(c.RateLimitStore == ratelimit.StoreRedis, validation.Required, is.DialString)),
+		validation.Field(&c.RedisDB, validation.Min(0)),
+		validation.Field(&c.RedisDialTimeout, validation.Required),
+	)
+}
+
+func validateSecretKeyLength(value interface{}) error {
+	if len(value.(string)) < minSecretKeyLength {
+		return fmt.Errorf("must be at least %d bytes outside develop", minSecretKeyLength)
+	}
+
+	return nil
+}
+
+// validateServiceAddrs accepts a list of "host:port" or a single "dns:///name:port" target
+func validateServiceAddrs(value interface{}) error {
+	addrs := value.([]string)
+	if len(addrs) == 1 && strings.HasPrefix(addrs[0], "dns:") {
+		return nil
+	}
+
+	for _, addr := range addrs {
+		if err := is.DialString.Validate(addr); err != nil {
+			return fmt.Errorf("%q: %w", addr, err)
+		}
+	}
+
+	return nil
+}
+
+func validateGrpcCode(value interface{}) error {
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(value.(string)))); err != nil {
+		return fmt.Errorf("unknown grpc status code %q", value)
+	}
+
+	return nil
+}
+
+func validateRateLimit(value interface{}) error {
+	if value.(string) == "" {
+		return nil
+	}
+
+	_, err := ratelimit.ParseLimit(value.(string))
+	return err
+}
+
+// validateUpstreamTLS only lets upstreams use plaintext connections in develop
+func (c *Config) validateUpstreamTLS(value interface{}) error {
+	if c.Environment == "develop" {
+		return nil
+	}
+
+	names := make([]string, 0, len(c.Upstreams))
+	for name, u := range c.Upstreams {
+		if !u.TLS {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return fmt.Errorf("insecure connections to %s are only allowed in develop", strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+// loadUpstream reads the "<prefix>_*" env vars of an upstream, falling back to defaults
+func loadUpstream(prefix string, defaults Upstream) Upstream {
+	u := Upstream{}
+
+	u.Host = cast.ToString(getOrReturnDefault(prefix+"_HOST", defaults.Host))
+	u.Port = cast.ToInt(getOrReturnDefault(prefix+"_PORT", defaults.Port))
+	u.Addrs = parseStringList(cast.ToString(getOrReturnDefault(prefix+"_ADDRS", strings.Join(defaults.Addrs, ","))))
+
+	u.LBPolicy = cast.ToString(getOrReturnDefault(prefix+"_LB_POLICY", defaults.LBPolicy))
+	u.HealthName = cast.ToString(getOrReturnDefault(prefix+"_HEALTH_NAME", defaults.HealthName))
+	u.HealthCheck = cast.ToBool(getOrReturnDefault(prefix+"_HEALTH_CHECK", defaults.HealthCheck))
+
+	u.TLS = cast.ToBool(getOrReturnDefault(prefix+"_TLS", defaults.TLS))
+	u.TLSCAFile = cast.ToString(getOrReturnDefault(prefix+"_TLS_CA_FILE", defaults.TLSCAFile))
+	u.TLSCertFile = cast.ToString(getOrReturnDefault(prefix+"_TLS_CERT_FILE", defaults.TLSCertFile))
+	u.TLSKeyFile = cast.ToString(getOrReturnDefault(prefix+"_TLS_KEY_FILE", defaults.TLSKeyFile))
+	u.TLSServerName = cast.ToString(getOrReturnDefault(prefix+"_TLS_SERVER_NAME", defaults.TLSServerName))
+
+	u.DialBlock = cast.ToBool(getOrReturnDefault(prefix+"_DIAL_BLOCK", defaults.DialBlock))
+	u.DialTimeout = cast.ToDuration(getOrReturnDefault(prefix+"_DIAL_TIMEOUT", defaults.DialTimeout))
+	u.Timeout = cast.ToDuration(getOrReturnDefault(prefix+"_TIMEOUT", defaults.Timeout))
+
+	u.KeepaliveTime = cast.ToDuration(getOrReturnDefault(prefix+"_KEEPALIVE_TIME", defaults.KeepaliveTime))
+	u.KeepaliveTimeout = cast.ToDuration(getOrReturnDefault(prefix+"_KEEPALIVE_TIMEOUT", defaults.KeepaliveTimeout))
+
+	u.MaxRecvMsgSize = cast.ToInt(getOrReturnDefault(prefix+"_MAX_RECV_MSG_SIZE", defaults.MaxRecvMsgSize))
+	u.MaxSendMsgSize = cast.ToInt(getOrReturnDefault(prefix+"_MAX_SEND_MSG_SIZE", defaults.MaxSendMsgSize))
+
+	return u
+}
+
+func validateIPOrCIDR(value interface{}) error {
+	if net.ParseIP(value.(string)) != nil {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(value.(string)); err != nil {
+		return errors.New("must be an ip address or a cidr")
+	}
+
+	return nil
+}
+
+// validateListenAddr accepts ":port" or "host:port" where host is an IPv4 address
+func validateListenAddr(value interface{}) error {
+	host, port, err := net.SplitHostPort(value.(string))
+	if err != nil {
+		return errors.New("must be in the form :port or ip:port")
+	}
+
+	if host != "" {
+		if err := helper.ValidateIp(host); err != nil {
+			return err
+		}
+	}
+
+	if err := helper.ValidatePort(port); err != nil {
+		return err
+	}
+
+	return validatePortNumber(cast.ToInt(port))
+}
+
+func validatePortNumber(value interface{}) error {
+	port := value.(int)
+	if err := helper.ValidatePort(strconv.Itoa(port)); err != nil {
+		return err
+	}
+	if port < 1 || port > 65535 {
+		return errors.New("port must be between 1 and 65535")
+	}
+
+	return nil
+}
+
+// parseRouteDurations parses "GET /v1/book=3s,POST /v1/book=10s" into a map keyed by "METHOD /full/path".
+// Malformed durations are kept as zero so Validate can report them.
+func parseRouteDurations(value string) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		route, duration, _ := strings.Cut(entry, "=")
+		durations[strings.TrimSpace(route)], _ = time.ParseDuration(strings.TrimSpace(duration))
+	}
+
+	return durations
+}
+
+// parseRouteValues parses "GET /v1/book=100/1m,POST /v1/book=10/1m" into a map keyed by "METHOD /full/path"
+func parseRouteValues(value string) map[string]string {
+	values := map[string]string{}
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		route, v, _ := strings.Cut(entry, "=")
+		values[strings.TrimSpace(route)] = strings.TrimSpace(v)
+	}
+
+	return values
+}
+
+// parseStringList parses "a, b" into []string{"a", "b"}
+func parseStringList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		items = append(items, strings.TrimSpace(item))
+	}
+
+	return items
+}
+
+// parseIntList parses "4,7" into []int{4, 7}; malformed items become 0 so Validate can report them
+func parseIntList(value string) []int {
+	var items []int
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		n, _ := strconv.Atoi(strings.TrimSpace(item))
+		items = append(items, n)
+	}
+
+	return items
+}
+
 func getOrReturnDefault(key string, defaultValue interface{}) interface{} {
 	val, exists := os.LookupEnv(key)
 
@@ -0,0 +1,131 @@
+package certs
+
+import (
+	"book-api-gateway/pkg/logger"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoCertificates is returned by LoadCertPool when the bundle holds no PEM certificates
+var ErrNoCertificates = errors.New("no certificates found")
+
+// Reloader serves a certificate and key pair from disk, picking up changes to either file.
+// Files are checked at most once per interval, on the handshake that needs the certificate.
+// A pair which fails to load is logged and the previous one kept.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	log      logger.Logger
+
+	mu          sync.Mutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	lastCheck   time.Time
+}
+
+// NewReloader loads the pair from certFile and keyFile and returns a Reloader checking them every interval
+func NewReloader(certFile, keyFile string, interval time.Duration, log logger.Logger) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		log:      log,
+	}
+
+	certModTime, keyModTime, err := r.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(certModTime, keyModTime); err != nil {
+		return nil, err
+	}
+	r.lastCheck = time.Now()
+
+	return r, nil
+}
+
+// GetCertificate serves the pair as tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.certificate(), nil
+}
+
+// GetClientCertificate serves the pair as tls.Config.GetClientCertificate
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.certificate(), nil
+}
+
+func (r *Reloader) certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.lastCheck) < r.interval {
+		return r.cert
+	}
+	r.lastCheck = now
+
+	certModTime, keyModTime, err := r.modTimes()
+	if err != nil {
+		r.log.Error("error while checking certificate files", logger.String("cert_file", r.certFile), logger.Error(err))
+		return r.cert
+	}
+	if certModTime.Equal(r.certModTime) && keyModTime.Equal(r.keyModTime) {
+		return r.cert
+	}
+
+	if err := r.load(certModTime, keyModTime); err != nil {
+		r.log.Error("error while reloading certificate, keeping the previous one", logger.String("cert_file", r.certFile), logger.Error(err))
+		return r.cert
+	}
+
+	r.log.Info("certificate reloaded", logger.String("cert_file", r.certFile))
+	return r.cert
+}
+
+func (r *Reloader) load(certModTime, keyModTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading certificate %s: %w", r.certFile, err)
+	}
+
+	r.cert = &cert
+	r.certModTime = certModTime
+	r.keyModTime = keyModTime
+	return nil
+}
+
+func (r *Reloader) modTimes() (certModTime, keyModTime time.Time, err error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// LoadCertPool reads a PEM bundle of CA certificates
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading ca bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("ca bundle %s: %w", caFile, ErrNoCertificates)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,68 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCursor is returned for malformed, tampered or foreign cursors
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+// Cursor is the position of a page in a listing. Clients only ever see it as an opaque signed token.
+type Cursor struct {
+	Offset int `json:"o"`
+	Limit  int `json:"l"`
+	// Filter fingerprints the listing filters the cursor was issued for
+	Filter string `json:"f"`
+}
+
+// Encode serializes c and signs it with key
+func Encode(c Cursor, key []byte) string {
+	payload, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sign(payload, key))
+}
+
+// Decode verifies the signature of token and returns the cursor it carries
+func Decode(token string, key []byte) (Cursor, error) {
+	var c Cursor
+
+	encodedPayload, encodedSig, found := strings.Cut(token, ".")
+	if !found {
+		return c, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, sign(payload, key)) {
+		return c, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, &c); err != nil || c.Offset < 0 || c.Limit <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Fingerprint returns a short stable digest of the given filter values, for Cursor.Filter
+func Fingerprint(values ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func sign(payload, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("cursor:"))
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,98 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testKey = []byte("test-cursor-key")
+
+// reencode replaces the payload of token with payload, keeping the original signature
+func reencode(token, payload string) string {
+	_, sig, _ := strings.Cut(token, ".")
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + sig
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{name: "filtered", cursor: Cursor{Offset: 20, Limit: 10, Filter: Fingerprint("name")}},
+		{name: "unfiltered", cursor: Cursor{Offset: 5, Limit: 5, Filter: Fingerprint()}},
+		{name: "first page", cursor: Cursor{Limit: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(Encode(tt.cursor, testKey), testKey)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got != tt.cursor {
+				t.Fatalf("Decode() = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsInvalidCursors(t *testing.T) {
+	valid := Encode(Cursor{Offset: 10, Limit: 10, Filter: "f"}, testKey)
+	payload, sig, _ := strings.Cut(valid, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "signed with another key", token: Encode(Cursor{Offset: 10, Limit: 10, Filter: "f"}, []byte("other key"))},
+		{name: "tampered payload", token: reencode(valid, `{"o":10,"l":1000,"f":"f"}`)},
+		{name: "tampered signature", token: payload + "." + strings.Repeat("A", len(sig))},
+		{name: "signature of another cursor", token: payload + "." + strings.SplitN(Encode(Cursor{Limit: 10}, testKey), ".", 2)[1]},
+		{name: "no signature", token: payload},
+		{name: "empty signature", token: payload + "."},
+		{name: "payload not base64", token: "!!." + sig},
+		{name: "signature not base64", token: payload + ".!!"},
+		{name: "empty", token: ""},
+		{name: "not json", token: signed(t, "not json")},
+		{name: "zero limit", token: signed(t, `{"l":0,"f":""}`)},
+		{name: "negative offset", token: signed(t, `{"o":-1,"l":10,"f":""}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.token, testKey)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("Decode() error = %v, want %v", err, ErrInvalidCursor)
+			}
+			if got != (Cursor{}) {
+				t.Fatalf("Decode() = %+v, want the zero cursor", got)
+			}
+		})
+	}
+}
+
+// signed returns payload correctly signed with testKey, to reach the checks made after the signature
+func signed(t *testing.T, payload string) string {
+	t.Helper()
+
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(sign([]byte(payload), testKey))
+}
+
+func TestFingerprint(t *testing.T) {
+	if Fingerprint("a", "b") != Fingerprint("a", "b") {
+		t.Fatalf("Fingerprint() is not stable")
+	}
+
+	distinct := [][]string{{"a", "b"}, {"ab"}, {"a", "", "b"}, {"b", "a"}, {}}
+	seen := map[string][]string{}
+	for _, values := range distinct {
+		fp := Fingerprint(values...)
+		if other, ok := seen[fp]; ok {
+			t.Fatalf("Fingerprint(%q) == Fingerprint(%q)", values, other)
+		}
+		seen[fp] = values
+	}
+}
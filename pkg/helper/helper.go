@@ -16,6 +16,11 @@ func GeneratePasswordHash(pass string) ([]byte, error) {
 	return bcrypt.GenerateFromPassword([]byte(pass), 10)
 }
 
+// ComparePasswordHash checks pass against a hash produced by GeneratePasswordHash
+func ComparePasswordHash(hash, pass string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pass))
+}
+
 func ValidatePassword(password string) error {
 	if password == "" {
 		return errors.New("password cannot be blank")
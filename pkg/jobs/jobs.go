@@ -0,0 +1,494 @@
+package jobs
+
+import (
+	"book-api-gateway/pkg/logger"
+	"book-api-gateway/storage"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned by Submit when every worker is busy and the queue has no room left
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrFinished is returned by Cancel for jobs that already reached a final status
+	ErrFinished = errors.New("job already finished")
+)
+
+const (
+	// progressSaveInterval throttles how often progress updates reach the store
+	progressSaveInterval = time.Second
+	// maxSweepInterval is the longest a finished job outlives its ttl
+	maxSweepInterval = time.Minute
+)
+
+// Func executes a job, reporting progress and results through run. It must return once ctx is done.
+type Func func(ctx context.Context, run *Run) error
+
+type task struct {
+	id      string
+	fn      Func
+	cleanup func()
+}
+
+// Manager runs submitted jobs on a fixed pool of workers and keeps their state in a storage.JobRepoI
+type Manager struct {
+	store     storage.JobRepoI
+	resultDir string
+	uploadDir string
+	ttl       time.Duration
+	log       logger.Logger
+	queue     chan task
+
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+
+	mu       sync.Mutex
+	running  map[string]context.CancelFunc
+	canceled map[string]bool
+	// uploads holds the upload files which are not removed yet, the sweep leaves them alone
+	uploads map[string]bool
+}
+
+// NewManager starts workers job workers with room for queueSize waiting jobs. Results are written to dir/results
+// and uploads to dir/uploads. Finished jobs are deleted along with their results ttl after they finished.
+// Jobs a previous process left queued or running cannot be resumed and are marked failed, and its uploads are deleted.
+func NewManager(store storage.JobRepoI, dir string, workers, queueSize int, ttl time.Duration, log logger.Logger) (*Manager, error) {
+	resultDir, uploadDir := filepath.Join(dir, "results"), filepath.Join(dir, "uploads")
+	for _, d := range []string{resultDir, uploadDir} {
+		if err := os.MkdirAll(d, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	jobs, err := store.GetAll(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.Finished() {
+			continue
+		}
+
+		finish(job, storage.JobFailed, "interrupted by a gateway restart")
+		if err := store.Update(context.Background(), job); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	m := &Manager{
+		store:     store,
+		resultDir: resultDir,
+		uploadDir: uploadDir,
+		ttl:       ttl,
+		log:       log,
+		queue:     make(chan task, queueSize),
+		ctx:       ctx,
+		stop:      stop,
+		running:   map[string]context.CancelFunc{},
+		canceled:  map[string]bool{},
+		uploads:   map[string]bool{},
+	}
+
+	if err := m.removeOrphanedResults(jobs); err != nil {
+		return nil, err
+	}
+	if err := m.removeUploads(time.Now()); err != nil {
+		return nil, err
+	}
+	m.sweep()
+
+	for i := 0; i < workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+
+	m.wg.Add(1)
+	go m.sweepLoop()
+
+	return m, nil
+}
+
+// Submit queues fn as a job of kind on behalf of owner. cleanup, if set, runs once the job is over or dropped.
+func (m *Manager) Submit(ctx context.Context, kind, owner string, fn Func, cleanup func()) (*storage.Job, error) {
+	if cleanup == nil {
+		cleanup = func() {}
+	}
+
+	id, err := newID()
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	job := &storage.Job{
+		Id:        id,
+		Kind:      kind,
+		Status:    storage.JobQueued,
+		Owner:     owner,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := m.store.Create(ctx, job); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	select {
+	case m.queue <- task{id: id, fn: fn, cleanup: cleanup}:
+		return job, nil
+	default:
+		cleanup()
+		finish(job, storage.JobFailed, ErrQueueFull.Error())
+		if err := m.store.Update(ctx, job); err != nil {
+			return nil, err
+		}
+		return nil, ErrQueueFull
+	}
+}
+
+// CreateUpload creates a file under the upload dir to keep the input of a job received with its request.
+// remove deletes the file and must be called once it is no longer needed, typically as the cleanup of the job.
+func (m *Manager) CreateUpload() (f *os.File, remove func(), err error) {
+	f, err = os.CreateTemp(m.uploadDir, "upload-*")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.Lock()
+	m.uploads[f.Name()] = true
+	m.mu.Unlock()
+
+	remove = func() {
+		m.mu.Lock()
+		delete(m.uploads, f.Name())
+		m.mu.Unlock()
+
+		if err := os.Remove(f.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			m.log.Error("error while removing job upload", logger.String("file", f.Name()), logger.Error(err))
+		}
+	}
+
+	return f, remove, nil
+}
+
+// Get returns the job with id, or storage.ErrNotFound
+func (m *Manager) Get(ctx context.Context, id string) (*storage.Job, error) {
+	return m.store.GetById(ctx, id)
+}
+
+// Cancel stops a queued or running job. Running jobs turn canceled once their Func returns.
+func (m *Manager) Cancel(ctx context.Context, id string) (*storage.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, err := m.store.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Finished() {
+		return job, ErrFinished
+	}
+
+	if cancel, ok := m.running[id]; ok {
+		m.canceled[id] = true
+		cancel()
+		return job, nil
+	}
+
+	finish(job, storage.JobCanceled, "")
+	return job, m.store.Update(ctx, job)
+}
+
+// Shutdown cancels running jobs, fails the queued ones and waits for the workers until ctx is done
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.stop()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for {
+		select {
+		case t := <-m.queue:
+			t.cleanup()
+			if job, err := m.store.GetById(ctx, t.id); err == nil && !job.Finished() {
+				finish(job, storage.JobFailed, "interrupted by gateway shutdown")
+				_ = m.store.Update(ctx, job)
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (m *Manager) work() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case t := <-m.queue:
+			m.run(t)
+		}
+	}
+}
+
+func (m *Manager) run(t task) {
+	defer t.cleanup()
+
+	m.mu.Lock()
+	job, err := m.store.GetById(m.ctx, t.id)
+	if err != nil || job.Status != storage.JobQueued {
+		// canceled while queued
+		m.mu.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+	m.running[job.Id] = cancel
+
+	now := time.Now().UTC()
+	job.Status = storage.JobRunning
+	job.StartedAt = &now
+	if err := m.store.Update(ctx, job); err != nil {
+		m.log.Error("error while starting job", logger.String("job_id", job.Id), logger.Error(err))
+	}
+	m.mu.Unlock()
+
+	m.log.Info("job started", logger.String("job_id", job.Id), logger.String("kind", job.Kind))
+
+	run := &Run{m: m, job: job}
+	err = t.fn(ctx, run)
+
+	m.mu.Lock()
+	canceled := m.canceled[job.Id]
+	delete(m.running, job.Id)
+	delete(m.canceled, job.Id)
+	m.mu.Unlock()
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	switch {
+	case canceled:
+		m.removeResult(job)
+		finish(job, storage.JobCanceled, "")
+	case err != nil && m.ctx.Err() != nil:
+		m.removeResult(job)
+		finish(job, storage.JobFailed, "interrupted by gateway shutdown")
+	case err != nil:
+		m.removeResult(job)
+		finish(job, storage.JobFailed, err.Error())
+	default:
+		finish(job, storage.JobSucceeded, "")
+	}
+
+	if err := m.store.Update(context.Background(), job); err != nil {
+		m.log.Error("error while finishing job", logger.String("job_id", job.Id), logger.Error(err))
+	}
+	m.log.Info("job finished", logger.String("job_id", job.Id), logger.String("status", job.Status), logger.String("error", job.Error))
+}
+
+func (m *Manager) removeResult(job *storage.Job) {
+	if job.ResultFile == "" {
+		return
+	}
+
+	if err := os.Remove(job.ResultFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		m.log.Error("error while removing job result", logger.String("job_id", job.Id), logger.Error(err))
+	}
+	job.ResultFile, job.ResultName, job.ResultContentType = "", "", ""
+}
+
+func (m *Manager) sweepLoop() {
+	defer m.wg.Done()
+
+	interval := m.ttl
+	if interval > maxSweepInterval {
+		interval = maxSweepInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.sweep()
+		}
+	}
+}
+
+// sweep deletes the jobs which finished more than ttl ago, together with their results, and the uploads
+// which were left behind for longer than ttl
+func (m *Manager) sweep() {
+	deadline := time.Now().Add(-m.ttl)
+	if err := m.removeUploads(deadline); err != nil {
+		m.log.Error("error while sweeping job uploads", logger.Error(err))
+	}
+
+	jobs, err := m.store.GetAll(m.ctx)
+	if err != nil {
+		m.log.Error("error while listing jobs to sweep", logger.Error(err))
+		return
+	}
+
+	for _, job := range jobs {
+		if !job.Finished() || job.FinishedAt == nil || job.FinishedAt.After(deadline) {
+			continue
+		}
+
+		m.removeResult(job)
+		if err := m.store.Delete(m.ctx, job.Id); err != nil {
+			m.log.Error("error while deleting expired job", logger.String("job_id", job.Id), logger.Error(err))
+			continue
+		}
+		m.log.Info("expired job deleted", logger.String("job_id", job.Id))
+	}
+}
+
+// removeOrphanedResults removes the files in resultDir which belong to none of jobs,
+// like the results of a previous process keeping its jobs in memory
+func (m *Manager) removeOrphanedResults(jobs []*storage.Job) error {
+	known := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		if job.ResultFile != "" {
+			known[filepath.Clean(job.ResultFile)] = true
+		}
+	}
+
+	entries, err := os.ReadDir(m.resultDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(m.resultDir, entry.Name())
+		if entry.IsDir() || known[path] {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			m.log.Error("error while removing orphaned job result", logger.String("file", path), logger.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// removeUploads removes the files in uploadDir last modified before deadline which no job holds anymore,
+// like the uploads of a previous process
+func (m *Manager) removeUploads(deadline time.Time) error {
+	entries, err := os.ReadDir(m.uploadDir)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, entry := range entries {
+		path := filepath.Join(m.uploadDir, entry.Name())
+		if entry.IsDir() || m.uploads[path] {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(deadline) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			m.log.Error("error while removing stale job upload", logger.String("file", path), logger.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// Run is the handle a running job reports its progress and results through. It is safe for concurrent use.
+type Run struct {
+	m        *Manager
+	mu       sync.Mutex
+	job      *storage.Job
+	lastSave time.Time
+}
+
+// Progress records that done out of total units are processed, total being 0 when unknown
+func (r *Run) Progress(done, total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.job.Done, r.job.Total = done, total
+	if time.Since(r.lastSave) < progressSaveInterval {
+		return
+	}
+	r.lastSave = time.Now()
+
+	if err := r.m.store.Update(context.Background(), r.job); err != nil {
+		r.m.log.Error("error while saving job progress", logger.String("job_id", r.job.Id), logger.Error(err))
+	}
+}
+
+// CreateResult creates the file clients download as the result of the job, under name with contentType
+func (r *Run) CreateResult(name, contentType string) (*os.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := filepath.Join(r.m.resultDir, r.job.Id)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	r.job.ResultFile, r.job.ResultName, r.job.ResultContentType = path, name, contentType
+	return f, nil
+}
+
+// SetSummary stores v as the JSON summary of the job
+func (r *Run) SetSummary(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.job.Summary = b
+	return nil
+}
+
+func finish(job *storage.Job, status, reason string) {
+	now := time.Now().UTC()
+	job.Status = status
+	job.Error = reason
+	job.FinishedAt = &now
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
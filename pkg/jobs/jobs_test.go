@@ -0,0 +1,275 @@
+package jobs
+
+import (
+	"book-api-gateway/pkg/logger"
+	"book-api-gateway/storage"
+	"book-api-gateway/storage/memory"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T, store storage.JobRepoI, dir string, workers int) *Manager {
+	m, err := NewManager(store, dir, workers, 10, time.Hour, logger.New(logger.LevelFatal, "test"))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	t.Cleanup(func() { _ = m.Shutdown(context.Background()) })
+
+	return m
+}
+
+// waitStatus polls the job with id until it has status, failing the test after a second
+func waitStatus(t *testing.T, m *Manager, id, status string) *storage.Job {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(5 * time.Millisecond) {
+		job, err := m.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+	}
+
+	t.Fatalf("job %s never reached status %s", id, status)
+	return nil
+}
+
+func writeFile(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestCancel(t *testing.T) {
+	t.Run("queued job", func(t *testing.T) {
+		// without workers the job stays queued
+		m := newTestManager(t, memory.NewJobRepo(), t.TempDir(), 0)
+
+		cleanedUp := make(chan struct{})
+		job, err := m.Submit(context.Background(), "export", "user-1", func(ctx context.Context, run *Run) error {
+			return nil
+		}, func() { close(cleanedUp) })
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+
+		if _, err := m.Cancel(context.Background(), job.Id); err != nil {
+			t.Fatalf("Cancel() error = %v", err)
+		}
+		waitStatus(t, m, job.Id, storage.JobCanceled)
+
+		if _, err := m.Cancel(context.Background(), job.Id); !errors.Is(err, ErrFinished) {
+			t.Fatalf("Cancel() of a canceled job error = %v, want %v", err, ErrFinished)
+		}
+
+		if err := m.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		select {
+		case <-cleanedUp:
+		default:
+			t.Fatalf("the cleanup of a dropped job did not run")
+		}
+	})
+
+	t.Run("running job", func(t *testing.T) {
+		m := newTestManager(t, memory.NewJobRepo(), t.TempDir(), 1)
+
+		started, cleanedUp := make(chan struct{}), make(chan struct{})
+		job, err := m.Submit(context.Background(), "export", "user-1", func(ctx context.Context, run *Run) error {
+			f, err := run.CreateResult("result.csv", "text/csv")
+			if err != nil {
+				return err
+			}
+			f.Close()
+
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}, func() { close(cleanedUp) })
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+
+		<-started
+		if _, err := m.Cancel(context.Background(), job.Id); err != nil {
+			t.Fatalf("Cancel() error = %v", err)
+		}
+		<-cleanedUp
+
+		job = waitStatus(t, m, job.Id, storage.JobCanceled)
+		if job.ResultFile != "" || job.FinishedAt == nil {
+			t.Fatalf("canceled job = %+v, want it finished without a result", job)
+		}
+		entries, _ := os.ReadDir(m.resultDir)
+		if len(entries) != 0 {
+			t.Fatalf("the result of a canceled job was kept")
+		}
+	})
+
+	t.Run("finished job", func(t *testing.T) {
+		m := newTestManager(t, memory.NewJobRepo(), t.TempDir(), 1)
+
+		job, err := m.Submit(context.Background(), "export", "user-1", func(ctx context.Context, run *Run) error {
+			return nil
+		}, nil)
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		waitStatus(t, m, job.Id, storage.JobSucceeded)
+
+		if _, err := m.Cancel(context.Background(), job.Id); !errors.Is(err, ErrFinished) {
+			t.Fatalf("Cancel() error = %v, want %v", err, ErrFinished)
+		}
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		m := newTestManager(t, memory.NewJobRepo(), t.TempDir(), 1)
+
+		if _, err := m.Cancel(context.Background(), "missing"); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("Cancel() error = %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+}
+
+func TestSweep(t *testing.T) {
+	dir := t.TempDir()
+	store := memory.NewJobRepo()
+	m := newTestManager(t, store, dir, 0)
+
+	expired, recent := time.Now().Add(-2*time.Hour).UTC(), time.Now().UTC()
+	expiredResult := filepath.Join(m.resultDir, "expired")
+	writeFile(t, expiredResult, expired)
+	recentResult := filepath.Join(m.resultDir, "recent")
+	writeFile(t, recentResult, recent)
+
+	for _, job := range []*storage.Job{
+		{Id: "expired", Status: storage.JobSucceeded, FinishedAt: &expired, ResultFile: expiredResult},
+		{Id: "recent", Status: storage.JobSucceeded, FinishedAt: &recent, ResultFile: recentResult},
+		{Id: "running", Status: storage.JobRunning},
+	} {
+		if err := store.Create(context.Background(), job); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	staleUpload := filepath.Join(m.uploadDir, "stale")
+	writeFile(t, staleUpload, expired)
+	heldUpload, remove, err := m.CreateUpload()
+	if err != nil {
+		t.Fatalf("CreateUpload() error = %v", err)
+	}
+	heldUpload.Close()
+	defer remove()
+	if err := os.Chtimes(heldUpload.Name(), expired, expired); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	m.sweep()
+
+	for _, tt := range []struct {
+		id   string
+		want bool
+	}{
+		{id: "expired", want: false},
+		{id: "recent", want: true},
+		{id: "running", want: true},
+	} {
+		_, err := store.GetById(context.Background(), tt.id)
+		if got := err == nil; got != tt.want {
+			t.Fatalf("job %s kept = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		path string
+		want bool
+	}{
+		{path: expiredResult, want: false},
+		{path: recentResult, want: true},
+		{path: staleUpload, want: false},
+		{path: heldUpload.Name(), want: true},
+	} {
+		if got := exists(tt.path); got != tt.want {
+			t.Fatalf("%s kept = %v, want %v", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+}
+
+func TestNewManagerRecoversFromRestart(t *testing.T) {
+	dir := t.TempDir()
+	resultDir, uploadDir := filepath.Join(dir, "results"), filepath.Join(dir, "uploads")
+	for _, d := range []string{resultDir, uploadDir} {
+		if err := os.MkdirAll(d, 0o700); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	keptResult := filepath.Join(resultDir, "succeeded")
+	writeFile(t, keptResult, now)
+	orphanedResult := filepath.Join(resultDir, "orphaned")
+	writeFile(t, orphanedResult, now)
+	leftUpload := filepath.Join(uploadDir, "upload-1")
+	writeFile(t, leftUpload, now)
+
+	store := memory.NewJobRepo()
+	for _, job := range []*storage.Job{
+		{Id: "queued", Status: storage.JobQueued},
+		{Id: "running", Status: storage.JobRunning, StartedAt: &now},
+		{Id: "succeeded", Status: storage.JobSucceeded, FinishedAt: &now, ResultFile: keptResult},
+	} {
+		if err := store.Create(context.Background(), job); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	newTestManager(t, store, dir, 1)
+
+	for _, tt := range []struct {
+		id        string
+		want      string
+		wantError string
+	}{
+		{id: "queued", want: storage.JobFailed, wantError: "interrupted by a gateway restart"},
+		{id: "running", want: storage.JobFailed, wantError: "interrupted by a gateway restart"},
+		{id: "succeeded", want: storage.JobSucceeded},
+	} {
+		job, err := store.GetById(context.Background(), tt.id)
+		if err != nil {
+			t.Fatalf("GetById(%s) error = %v", tt.id, err)
+		}
+		if job.Status != tt.want || job.Error != tt.wantError || job.FinishedAt == nil {
+			t.Fatalf("job %s = %s (%q), want %s (%q)", tt.id, job.Status, job.Error, tt.want, tt.wantError)
+		}
+	}
+
+	for _, tt := range []struct {
+		path string
+		want bool
+	}{
+		{path: keptResult, want: true},
+		{path: orphanedResult, want: false},
+		{path: leftUpload, want: false},
+	} {
+		if got := exists(tt.path); got != tt.want {
+			t.Fatalf("%s kept = %v, want %v", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+}
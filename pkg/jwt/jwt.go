@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	jwtgo "github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	// ErrMissingToken ...
+	ErrMissingToken = errors.New("authorization token is missing")
+	// ErrInvalidToken ...
+	ErrInvalidToken = errors.New("authorization token is invalid")
+	// ErrExpiredToken ...
+	ErrExpiredToken = errors.New("authorization token is expired")
+)
+
+const (
+	// AccessToken authorizes calls to the api
+	AccessToken = "access"
+	// RefreshToken can only be exchanged for a new token pair
+	RefreshToken = "refresh"
+)
+
+// Claims is the payload carried by gateway tokens
+type Claims struct {
+	UserID    string `json:"user_id"`
+	UserType  string `json:"user_type"`
+	TokenType string `json:"token_type"`
+	jwtgo.RegisteredClaims
+}
+
+// NewClaims returns tokenType claims for the user which expire after ttl and carry a random token id
+func NewClaims(userID, userType, tokenType string, ttl time.Duration) (Claims, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Claims{}, err
+	}
+
+	now := time.Now()
+	return Claims{
+		UserID:    userID,
+		UserType:  userType,
+		TokenType: tokenType,
+		RegisteredClaims: jwtgo.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			IssuedAt:  jwtgo.NewNumericDate(now),
+			ExpiresAt: jwtgo.NewNumericDate(now.Add(ttl)),
+		},
+	}, nil
+}
+
+// GenerateJWT signs claims with HS256 using signingKey
+func GenerateJWT(claims Claims, signingKey []byte) (string, error) {
+	return jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, claims).SignedString(signingKey)
+}
+
+// ExtractClaims parses tokenStr, verifies its HS256 signature and expiry and returns its claims
+func ExtractClaims(tokenStr string, signingKey []byte) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwtgo.ParseWithClaims(tokenStr, claims, func(t *jwtgo.Token) (interface{}, error) {
+		if t.Method != jwtgo.SigningMethodHS256 {
+			return nil, ErrInvalidToken
+		}
+		return signingKey, nil
+	})
+	if err != nil {
+		var validationErr *jwtgo.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwtgo.ValidationErrorExpired != 0 {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	if !token.Valid || claims.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header value
+func BearerToken(header string) (string, error) {
+	if header == "" {
+		return "", ErrMissingToken
+	}
+
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", ErrInvalidToken
+	}
+
+	return strings.TrimSpace(token), nil
+}
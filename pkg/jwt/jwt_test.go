@@ -0,0 +1,153 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	jwtgo "github.com/golang-jwt/jwt/v4"
+)
+
+var testKey = []byte("test-signing-key-of-at-least-32-bytes")
+
+func mustSign(t *testing.T, claims Claims, key []byte) string {
+	t.Helper()
+
+	token, err := GenerateJWT(claims, key)
+	if err != nil {
+		t.Fatalf("GenerateJWT() error = %v", err)
+	}
+	return token
+}
+
+func mustClaims(t *testing.T, userID, tokenType string, ttl time.Duration) Claims {
+	t.Helper()
+
+	claims, err := NewClaims(userID, "admin", tokenType, ttl)
+	if err != nil {
+		t.Fatalf("NewClaims() error = %v", err)
+	}
+	return claims
+}
+
+// tamper swaps the payload of token for one carrying claims, keeping the original signature
+func tamper(t *testing.T, token string, claims Claims) string {
+	t.Helper()
+
+	forged := mustSign(t, claims, []byte("some other key"))
+	parts, forgedParts := strings.Split(token, "."), strings.Split(forged, ".")
+	return parts[0] + "." + forgedParts[1] + "." + parts[2]
+}
+
+func TestExtractClaims(t *testing.T) {
+	valid := mustClaims(t, "user-1", AccessToken, time.Hour)
+	validToken := mustSign(t, valid, testKey)
+
+	escalated := valid
+	escalated.UserType = "superadmin"
+
+	noUser := mustClaims(t, "", AccessToken, time.Hour)
+
+	none, err := jwtgo.NewWithClaims(jwtgo.SigningMethodNone, valid).SignedString(jwtgo.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("signing with none error = %v", err)
+	}
+
+	hs512, err := jwtgo.NewWithClaims(jwtgo.SigningMethodHS512, valid).SignedString(testKey)
+	if err != nil {
+		t.Fatalf("signing with HS512 error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "valid", token: validToken},
+		{name: "refresh tokens parse too", token: mustSign(t, mustClaims(t, "user-1", RefreshToken, time.Hour), testKey)},
+		{name: "expired", token: mustSign(t, mustClaims(t, "user-1", AccessToken, -time.Minute), testKey), wantErr: ErrExpiredToken},
+		{name: "signed with another key", token: mustSign(t, valid, []byte("another-signing-key-of-32-bytes!!")), wantErr: ErrInvalidToken},
+		{name: "tampered payload", token: tamper(t, validToken, escalated), wantErr: ErrInvalidToken},
+		{name: "alg none", token: none, wantErr: ErrInvalidToken},
+		{name: "other hmac algorithm", token: hs512, wantErr: ErrInvalidToken},
+		{name: "no user id", token: mustSign(t, noUser, testKey), wantErr: ErrInvalidToken},
+		{name: "truncated signature", token: validToken[:len(validToken)-4], wantErr: ErrInvalidToken},
+		{name: "not a jwt", token: "not-a-jwt", wantErr: ErrInvalidToken},
+		{name: "empty", token: "", wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ExtractClaims(tt.token, testKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ExtractClaims() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if claims != nil {
+					t.Fatalf("ExtractClaims() claims = %+v, want nil", claims)
+				}
+				return
+			}
+			if claims.UserID != "user-1" || claims.UserType != "admin" {
+				t.Fatalf("ExtractClaims() claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestExtractClaimsRejectsRSAPublicKeyConfusion(t *testing.T) {
+	// a token whose header claims RS256 must not be verified with the hmac key
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	token := mustSign(t, mustClaims(t, "user-1", AccessToken, time.Hour), testKey)
+	parts := strings.Split(token, ".")
+
+	if _, err := ExtractClaims(header+"."+parts[1]+"."+parts[2], testKey); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("ExtractClaims() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestNewClaims(t *testing.T) {
+	a := mustClaims(t, "user-1", AccessToken, time.Hour)
+	b := mustClaims(t, "user-1", AccessToken, time.Hour)
+
+	if a.ID == "" || a.ID == b.ID {
+		t.Fatalf("NewClaims() token ids = %q and %q, want distinct random ids", a.ID, b.ID)
+	}
+	if a.TokenType != AccessToken {
+		t.Fatalf("NewClaims() token type = %q, want %q", a.TokenType, AccessToken)
+	}
+	if got := a.ExpiresAt.Sub(a.IssuedAt.Time); got != time.Hour {
+		t.Fatalf("NewClaims() lifetime = %s, want %s", got, time.Hour)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    string
+		wantErr error
+	}{
+		{header: "Bearer abc.def.ghi", want: "abc.def.ghi"},
+		{header: "bearer abc", want: "abc"},
+		{header: "Bearer  abc ", want: "abc"},
+		{header: "", wantErr: ErrMissingToken},
+		{header: "Bearer", wantErr: ErrInvalidToken},
+		{header: "Bearer   ", wantErr: ErrInvalidToken},
+		{header: "Basic abc", wantErr: ErrInvalidToken},
+		{header: "abc.def.ghi", wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := BearerToken(tt.header)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BearerToken(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("BearerToken(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package lb
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+
+	// registers the client side health check used when a service config enables healthCheckConfig
+	_ "google.golang.org/grpc/health"
+)
+
+const (
+	// RoundRobin sends calls to the healthy backends in turn
+	RoundRobin = "round_robin"
+	// LeastRequest sends each call to the healthy backend with the fewest calls in flight
+	LeastRequest = "least_request"
+)
+
+// policies maps the policy names used in config to the names their balancers are registered under
+var policies = map[string]string{
+	RoundRobin:   "gateway_round_robin",
+	LeastRequest: "gateway_least_request",
+}
+
+func init() {
+	balancer.Register(&trackingBuilder{
+		Builder: base.NewBalancerBuilder(policies[RoundRobin], &roundRobinPickerBuilder{}, base.Config{HealthCheck: true}),
+	})
+	balancer.Register(&trackingBuilder{
+		Builder: base.NewBalancerBuilder(policies[LeastRequest], &leastRequestPickerBuilder{}, base.Config{HealthCheck: true}),
+	})
+}
+
+// ServiceConfig returns the grpc service config of the upstream called name selecting policy and,
+// unless healthCheck is false, health checking every backend with grpc.health.v1 for healthServiceName
+func ServiceConfig(name, policy string, healthCheck bool, healthServiceName string) string {
+	config := map[string]interface{}{
+		"loadBalancingConfig": []map[string]interface{}{
+			{policies[policy]: lbConfig{Upstream: name}},
+		},
+	}
+	if healthCheck {
+		config["healthCheckConfig"] = map[string]interface{}{"serviceName": healthServiceName}
+	}
+
+	data, _ := json.Marshal(config)
+	return string(data)
+}
+
+// Backend is the state of one backend address of a balanced connection
+type Backend struct {
+	Upstream string
+	Target   string
+	Address  string
+	State    string
+	Error    string
+}
+
+var (
+	trackersMu sync.Mutex
+	trackers   = map[*tracker]bool{}
+)
+
+// Backends returns the backends of every connection balanced by this package, sorted by upstream and address
+func Backends() []Backend {
+	trackersMu.Lock()
+	defer trackersMu.Unlock()
+
+	var backends []Backend
+	for t := range trackers {
+		backends = append(backends, t.backends()...)
+	}
+
+	sort.Slice(backends, func(i, j int) bool {
+		if backends[i].Upstream != backends[j].Upstream {
+			return backends[i].Upstream < backends[j].Upstream
+		}
+		return backends[i].Address < backends[j].Address
+	})
+
+	return backends
+}
+
+// lbConfig is the config of the balancers registered by this package
+type lbConfig struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+
+	Upstream string `json:"upstream"`
+}
+
+// trackingBuilder builds balancers which record the address and state of each of their subconns
+type trackingBuilder struct {
+	balancer.Builder
+}
+
+func (b *trackingBuilder) ParseConfig(data json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	config := &lbConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (b *trackingBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	t := &tracker{
+		target:   cc.Target(),
+		subConns: map[balancer.SubConn]*Backend{},
+	}
+	t.Balancer = b.Builder.Build(&trackingClientConn{ClientConn: cc, tracker: t}, opts)
+
+	trackersMu.Lock()
+	trackers[t] = true
+	trackersMu.Unlock()
+
+	return t
+}
+
+type tracker struct {
+	balancer.Balancer
+	target string
+
+	mu       sync.Mutex
+	upstream string
+	subConns map[balancer.SubConn]*Backend
+}
+
+func (t *tracker) UpdateClientConnState(state balancer.ClientConnState) error {
+	if config, ok := state.BalancerConfig.(*lbConfig); ok {
+		t.mu.Lock()
+		t.upstream = config.Upstream
+		t.mu.Unlock()
+	}
+
+	return t.Balancer.UpdateClientConnState(state)
+}
+
+func (t *tracker) UpdateSubConnState(sc balancer.SubConn, state balancer.SubConnState) {
+	t.mu.Lock()
+	if backend, ok := t.subConns[sc]; ok {
+		backend.State = state.ConnectivityState.String()
+		backend.Error = ""
+		if state.ConnectivityState == connectivity.TransientFailure && state.ConnectionError != nil {
+			backend.Error = state.ConnectionError.Error()
+		}
+	}
+	t.mu.Unlock()
+
+	t.Balancer.UpdateSubConnState(sc, state)
+}
+
+func (t *tracker) ExitIdle() {
+	if exitIdler, ok := t.Balancer.(balancer.ExitIdler); ok {
+		exitIdler.ExitIdle()
+	}
+}
+
+func (t *tracker) Close() {
+	trackersMu.Lock()
+	delete(trackers, t)
+	trackersMu.Unlock()
+
+	t.Balancer.Close()
+}
+
+func (t *tracker) backends() []Backend {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	backends := make([]Backend, 0, len(t.subConns))
+	for _, backend := range t.subConns {
+		b := *backend
+		b.Upstream = t.upstream
+		backends = append(backends, b)
+	}
+
+	return backends
+}
+
+// trackingClientConn records the subconns the balancer creates and removes
+type trackingClientConn struct {
+	balancer.ClientConn
+	tracker *tracker
+}
+
+func (cc *trackingClientConn) NewSubConn(addrs []resolver.Address, opts balancer.NewSubConnOptions) (balancer.SubConn, error) {
+	sc, err := cc.ClientConn.NewSubConn(addrs, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.tracker.mu.Lock()
+	cc.tracker.subConns[sc] = &Backend{
+		Target:  cc.tracker.target,
+		Address: addrs[0].Addr,
+		State:   connectivity.Idle.String(),
+	}
+	cc.tracker.mu.Unlock()
+
+	return sc, nil
+}
+
+func (cc *trackingClientConn) RemoveSubConn(sc balancer.SubConn) {
+	cc.tracker.mu.Lock()
+	delete(cc.tracker.subConns, sc)
+	cc.tracker.mu.Unlock()
+
+	cc.ClientConn.RemoveSubConn(sc)
+}
+
+func (cc *trackingClientConn) UpdateAddresses(sc balancer.SubConn, addrs []resolver.Address) {
+	cc.tracker.mu.Lock()
+	if backend, ok := cc.tracker.subConns[sc]; ok && len(addrs) > 0 {
+		backend.Address = addrs[0].Addr
+	}
+	cc.tracker.mu.Unlock()
+
+	cc.ClientConn.UpdateAddresses(sc, addrs)
+}
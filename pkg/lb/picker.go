@@ -0,0 +1,81 @@
+package lb
+
+import (
+	"sync/atomic"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+)
+
+type roundRobinPickerBuilder struct{}
+
+func (*roundRobinPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	subConns := make([]balancer.SubConn, 0, len(info.ReadySCs))
+	for sc := range info.ReadySCs {
+		subConns = append(subConns, sc)
+	}
+
+	return &roundRobinPicker{subConns: subConns}
+}
+
+type roundRobinPicker struct {
+	subConns []balancer.SubConn
+	next     uint32
+}
+
+func (p *roundRobinPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	n := atomic.AddUint32(&p.next, 1)
+	return balancer.PickResult{SubConn: p.subConns[n%uint32(len(p.subConns))]}, nil
+}
+
+type leastRequestPickerBuilder struct{}
+
+func (*leastRequestPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	backends := make([]*leastRequestBackend, 0, len(info.ReadySCs))
+	for sc := range info.ReadySCs {
+		backends = append(backends, &leastRequestBackend{subConn: sc})
+	}
+
+	return &leastRequestPicker{backends: backends}
+}
+
+type leastRequestBackend struct {
+	subConn  balancer.SubConn
+	inFlight int64
+}
+
+// leastRequestPicker counts calls in flight per backend. Counts start over whenever the set of ready
+// backends changes, as each picker only sees the calls it picked itself.
+type leastRequestPicker struct {
+	backends []*leastRequestBackend
+	next     uint32
+}
+
+func (p *leastRequestPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	// start the scan at a rotating offset so ties are spread across backends
+	start := int(atomic.AddUint32(&p.next, 1) % uint32(len(p.backends)))
+
+	picked := p.backends[start]
+	for i := 1; i < len(p.backends); i++ {
+		backend := p.backends[(start+i)%len(p.backends)]
+		if atomic.LoadInt64(&backend.inFlight) < atomic.LoadInt64(&picked.inFlight) {
+			picked = backend
+		}
+	}
+
+	atomic.AddInt64(&picked.inFlight, 1)
+	return balancer.PickResult{
+		SubConn: picked.subConn,
+		Done: func(balancer.DoneInfo) {
+			atomic.AddInt64(&picked.inFlight, -1)
+		},
+	}, nil
+}
@@ -0,0 +1,99 @@
+package otp
+
+import (
+	"book-api-gateway/pkg/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// ChannelSMS delivers codes to phone numbers
+	ChannelSMS = "sms"
+	// ChannelEmail delivers codes to email addresses
+	ChannelEmail = "email"
+
+	// SenderLog is the development sender which only writes codes to the log, never use it outside develop
+	SenderLog = "log"
+	// SenderWebhook posts codes to a delivery service which sends the sms or email
+	SenderWebhook = "webhook"
+)
+
+// Options configures the senders which deliver codes through another service
+type Options struct {
+	WebhookURL     string
+	WebhookTimeout time.Duration
+}
+
+// Sender delivers one-time codes to a recipient over a channel
+type Sender interface {
+	Send(ctx context.Context, channel, recipient, code string) error
+}
+
+// NewSender returns the Sender registered under kind
+func NewSender(kind string, opts Options, log logger.Logger) (Sender, error) {
+	switch kind {
+	case SenderLog:
+		log.Warn("otp codes are written to the log instead of being delivered")
+		return &logSender{log: log}, nil
+	case SenderWebhook:
+		if opts.WebhookURL == "" {
+			return nil, fmt.Errorf("otp sender %q needs a webhook url", kind)
+		}
+		return &webhookSender{url: opts.WebhookURL, client: &http.Client{Timeout: opts.WebhookTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown otp sender %q", kind)
+	}
+}
+
+type logSender struct {
+	log logger.Logger
+}
+
+func (s *logSender) Send(ctx context.Context, channel, recipient, code string) error {
+	s.log.Info("otp code",
+		logger.String("channel", channel),
+		logger.String("recipient", recipient),
+		logger.String("code", code),
+	)
+	return nil
+}
+
+type webhookSender struct {
+	url    string
+	client *http.Client
+}
+
+type webhookMessage struct {
+	Channel   string `json:"channel"`
+	Recipient string `json:"recipient"`
+	Code      string `json:"code"`
+}
+
+func (s *webhookSender) Send(ctx context.Context, channel, recipient, code string) error {
+	body, err := json.Marshal(webhookMessage{Channel: channel, Recipient: recipient, Code: code})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("otp webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("otp webhook answered %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package otp
+
+import (
+	"book-api-gateway/pkg/logger"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "delivered", status: http.StatusNoContent},
+		{name: "rejected", status: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got webhookMessage
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("decoding webhook body: %v", err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			sender, err := NewSender(SenderWebhook, Options{WebhookURL: srv.URL, WebhookTimeout: time.Second}, logger.New(logger.LevelFatal, "test"))
+			if err != nil {
+				t.Fatalf("NewSender() error = %v", err)
+			}
+
+			err = sender.Send(context.Background(), ChannelSMS, "+15550100", "123456")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			want := webhookMessage{Channel: ChannelSMS, Recipient: "+15550100", Code: "123456"}
+			if got != want {
+				t.Fatalf("webhook got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestNewSenderRejectsIncompleteConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		opts Options
+	}{
+		{name: "unknown sender", kind: "carrier-pigeon"},
+		{name: "webhook without url", kind: SenderWebhook},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSender(tt.kind, tt.opts, logger.New(logger.LevelFatal, "test")); err == nil {
+				t.Fatalf("NewSender() error = nil, want an error")
+			}
+		})
+	}
+}
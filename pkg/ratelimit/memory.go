@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped from the memory store
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+type memoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns a Store keeping buckets in process memory
+func NewMemoryStore() Store {
+	return &memoryStore{
+		now:       time.Now,
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *memoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.last)
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+float64(elapsed)/float64(limit.interval()))
+	b.last = now
+	b.period = limit.Period
+
+	if b.tokens < 1 {
+		return newResult(limit, b.tokens, false), nil
+	}
+
+	b.tokens--
+	return newResult(limit, b.tokens, true), nil
+}
+
+// sweep drops buckets which have been idle long enough to be full again
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
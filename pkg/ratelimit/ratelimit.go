@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// StoreMemory keeps buckets in the gateway process, each replica limiting on its own
+	StoreMemory = "memory"
+	// StoreRedis keeps buckets in redis so all replicas share them
+	StoreRedis = "redis"
+)
+
+// ErrInvalidLimit is returned by ParseLimit for values not in the form "<requests>/<period>"
+var ErrInvalidLimit = errors.New(`rate limit must be in the form "<requests>/<period>", e.g. "100/1m"`)
+
+// Limit is a token bucket holding up to Burst tokens which refills at Burst tokens per Period
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// ParseLimit parses "100/1m" or "10/s" into a Limit
+func ParseLimit(value string) (Limit, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, ErrInvalidLimit
+	}
+
+	burst, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || burst < 1 {
+		return Limit{}, ErrInvalidLimit
+	}
+
+	period = strings.TrimSpace(period)
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, ErrInvalidLimit
+	}
+
+	return Limit{Burst: burst, Period: d}, nil
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Burst, l.Period)
+}
+
+// interval is the time it takes to refill one token
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Burst)
+}
+
+// Result is the state of a bucket after a request tried to take a token from it
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token is available, zero when Allowed
+}
+
+// Store takes tokens from the bucket kept under key, creating a full one on first use
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// newResult builds the Result for a bucket left with tokens after the request
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	interval := float64(limit.interval())
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(limit.Burst) - tokens) * interval),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) * interval)
+	}
+
+	return res
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// takeScript refills and takes from the bucket atomically on the redis server.
+// KEYS[1] is the bucket, ARGV is burst, refill interval in microseconds and the current time in microseconds.
+// It returns whether a token was taken and the tokens left, as a string since redis truncates numbers to integers.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / interval)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * interval / 1000))
+
+return {allowed, tostring(tokens)}
+`)
+
+type redisStore struct {
+	client redis.Scripter
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore returns a Store keeping buckets in redis under prefix, shared by every gateway replica
+func NewRedisStore(client redis.Scripter, prefix string) Store {
+	return &redisStore{
+		client: client,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+func (s *redisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	interval := limit.interval().Microseconds()
+	if interval < 1 {
+		interval = 1
+	}
+
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Burst, interval, s.now().UnixMicro(),
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokensStr, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return newResult(limit, tokens, allowed == 1), nil
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func newTestMemoryStore(t *testing.T, c *clock) Store {
+	s := NewMemoryStore().(*memoryStore)
+	s.now = c.now
+	return s
+}
+
+func newTestRedisStore(t *testing.T, c *clock) Store {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	s := NewRedisStore(client, "test:").(*redisStore)
+	s.now = c.now
+	return s
+}
+
+var stores = []struct {
+	name     string
+	newStore func(t *testing.T, c *clock) Store
+}{
+	{name: "memory", newStore: newTestMemoryStore},
+	{name: "redis", newStore: newTestRedisStore},
+}
+
+func TestStoreTake(t *testing.T) {
+	limit := Limit{Burst: 3, Period: 3 * time.Second}
+
+	steps := []struct {
+		name           string
+		advance        time.Duration
+		wantAllowed    bool
+		wantRemaining  int
+		wantRetryAfter time.Duration
+	}{
+		{name: "first request gets a full bucket", wantAllowed: true, wantRemaining: 2},
+		{name: "second", wantAllowed: true, wantRemaining: 1},
+		{name: "third empties the bucket", wantAllowed: true, wantRemaining: 0},
+		{name: "empty bucket rejects", wantAllowed: false, wantRemaining: 0, wantRetryAfter: time.Second},
+		{name: "half a token is not enough", advance: 500 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantRetryAfter: 500 * time.Millisecond},
+		{name: "refilled token is taken", advance: 500 * time.Millisecond, wantAllowed: true, wantRemaining: 0},
+		{name: "refill stops at burst", advance: time.Minute, wantAllowed: true, wantRemaining: 2},
+	}
+
+	for _, store := range stores {
+		t.Run(store.name, func(t *testing.T) {
+			c := &clock{t: time.Unix(1700000000, 0)}
+			s := store.newStore(t, c)
+
+			for _, step := range steps {
+				c.t = c.t.Add(step.advance)
+
+				res, err := s.Take(context.Background(), "user:1", limit)
+				if err != nil {
+					t.Fatalf("%s: Take() error = %v", step.name, err)
+				}
+				if res.Allowed != step.wantAllowed || res.Remaining != step.wantRemaining || res.RetryAfter != step.wantRetryAfter {
+					t.Fatalf("%s: Take() = %+v, want allowed %v, remaining %d, retry after %s",
+						step.name, res, step.wantAllowed, step.wantRemaining, step.wantRetryAfter)
+				}
+				if res.Limit != limit.Burst {
+					t.Fatalf("%s: Take() limit = %d, want %d", step.name, res.Limit, limit.Burst)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreKeysAreSeparate(t *testing.T) {
+	limit := Limit{Burst: 1, Period: time.Minute}
+
+	for _, store := range stores {
+		t.Run(store.name, func(t *testing.T) {
+			s := store.newStore(t, &clock{t: time.Unix(1700000000, 0)})
+
+			for _, key := range []string{"user:1", "user:2", "ip:127.0.0.1"} {
+				res, err := s.Take(context.Background(), key, limit)
+				if err != nil {
+					t.Fatalf("Take(%q) error = %v", key, err)
+				}
+				if !res.Allowed {
+					t.Fatalf("Take(%q) was rejected by another key's bucket", key)
+				}
+			}
+
+			res, err := s.Take(context.Background(), "user:1", limit)
+			if err != nil {
+				t.Fatalf("Take() error = %v", err)
+			}
+			if res.Allowed {
+				t.Fatalf("Take() on an empty bucket was allowed")
+			}
+		})
+	}
+}
+
+func TestRedisStoreExpiresIdleBuckets(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	s := NewRedisStore(client, "test:")
+	limit := Limit{Burst: 10, Period: 5 * time.Second}
+
+	if _, err := s.Take(context.Background(), "user:1", limit); err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+
+	if !mr.Exists("test:user:1") {
+		t.Fatalf("bucket was not stored under the prefixed key")
+	}
+	if ttl := mr.TTL("test:user:1"); ttl != limit.Period {
+		t.Fatalf("bucket ttl = %s, want the time to refill it, %s", ttl, limit.Period)
+	}
+
+	mr.FastForward(limit.Period)
+	if mr.Exists("test:user:1") {
+		t.Fatalf("idle bucket was not expired")
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{value: "100/1m", want: Limit{Burst: 100, Period: time.Minute}},
+		{value: "10/s", want: Limit{Burst: 10, Period: time.Second}},
+		{value: " 5 / 30s ", want: Limit{Burst: 5, Period: 30 * time.Second}},
+		{value: "", wantErr: true},
+		{value: "100", wantErr: true},
+		{value: "0/1m", wantErr: true},
+		{value: "-1/1m", wantErr: true},
+		{value: "10/0s", wantErr: true},
+		{value: "10/forever", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseLimit(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseLimit(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const (
+	// PermissionAll grants every permission
+	PermissionAll = "*"
+	// PermissionBookWrite allows creating, updating and deleting books
+	PermissionBookWrite = "book:write"
+	// PermissionBookCategoryWrite allows creating, updating and deleting book categories
+	PermissionBookCategoryWrite = "book_category:write"
+	// PermissionApiKeyManage allows minting, listing and revoking api keys
+	PermissionApiKeyManage = "api_key:manage"
+	// PermissionAdminRead allows reading gateway internals such as upstream metrics
+	PermissionAdminRead = "admin:read"
+)
+
+// Policy maps user types (roles) to the permissions they hold
+type Policy struct {
+	roles map[string]map[string]bool
+}
+
+// NewPolicy builds a Policy from a role -> permissions mapping
+func NewPolicy(roles map[string][]string) *Policy {
+	p := &Policy{roles: make(map[string]map[string]bool, len(roles))}
+	for role, permissions := range roles {
+		p.roles[role] = make(map[string]bool, len(permissions))
+		for _, permission := range permissions {
+			p.roles[role][permission] = true
+		}
+	}
+
+	return p
+}
+
+// DefaultPolicy lets superadmin do anything and admin write books and categories and manage api keys
+func DefaultPolicy() *Policy {
+	return NewPolicy(map[string][]string{
+		"superadmin": {PermissionAll},
+		"admin":      {PermissionBookWrite, PermissionBookCategoryWrite, PermissionApiKeyManage},
+	})
+}
+
+// Load reads a JSON role -> permissions mapping from path, or returns DefaultPolicy if path is empty
+func Load(path string) (*Policy, error) {
+	if path == "" {
+		return DefaultPolicy(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rbac policy: %w", err)
+	}
+
+	roles := map[string][]string{}
+	if err := json.Unmarshal(data, &roles); err != nil {
+		return nil, fmt.Errorf("parsing rbac policy %s: %w", path, err)
+	}
+
+	return NewPolicy(roles), nil
+}
+
+// Allowed reports whether role holds permission
+func (p *Policy) Allowed(role, permission string) bool {
+	permissions, ok := p.roles[role]
+	if !ok {
+		return false
+	}
+
+	return permissions[PermissionAll] || permissions[permission]
+}
@@ -0,0 +1,98 @@
+package rbac
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	p := DefaultPolicy()
+
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{role: "superadmin", permission: PermissionBookWrite, want: true},
+		{role: "superadmin", permission: PermissionAdminRead, want: true},
+		{role: "superadmin", permission: "anything:else", want: true},
+		{role: "admin", permission: PermissionBookWrite, want: true},
+		{role: "admin", permission: PermissionBookCategoryWrite, want: true},
+		{role: "admin", permission: PermissionApiKeyManage, want: true},
+		{role: "admin", permission: PermissionAdminRead, want: false},
+		{role: "user", permission: PermissionBookWrite, want: false},
+		{role: "", permission: PermissionBookWrite, want: false},
+		{role: "Admin", permission: PermissionBookWrite, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+"/"+tt.permission, func(t *testing.T) {
+			if got := p.Allowed(tt.role, tt.permission); got != tt.want {
+				t.Fatalf("Allowed(%q, %q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+		return path
+	}
+
+	policy := write("policy.json", `{"editor": ["book:write"], "ops": ["admin:read"], "root": ["*"]}`)
+
+	p, err := Load(policy)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{role: "editor", permission: PermissionBookWrite, want: true},
+		{role: "editor", permission: PermissionBookCategoryWrite, want: false},
+		{role: "ops", permission: PermissionAdminRead, want: true},
+		{role: "ops", permission: PermissionBookWrite, want: false},
+		{role: "root", permission: PermissionApiKeyManage, want: true},
+		{role: "superadmin", permission: PermissionBookWrite, want: false},
+		{role: "admin", permission: PermissionBookWrite, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+"/"+tt.permission, func(t *testing.T) {
+			if got := p.Allowed(tt.role, tt.permission); got != tt.want {
+				t.Fatalf("Allowed(%q, %q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("empty path", func(t *testing.T) {
+		p, err := Load("")
+		if err != nil {
+			t.Fatalf("Load(\"\") error = %v", err)
+		}
+		if !p.Allowed("admin", PermissionBookWrite) {
+			t.Fatalf("Load(\"\") did not return the default policy")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+			t.Fatalf("Load() of a missing file error = nil")
+		}
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		if _, err := Load(write("bad.json", `{"editor": "book:write"}`)); err == nil {
+			t.Fatalf("Load() of a malformed file error = nil")
+		}
+	})
+}
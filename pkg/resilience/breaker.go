@@ -0,0 +1,191 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// StateClosed lets every call through while counting consecutive failures
+	StateClosed State = iota
+	// StateOpen rejects every call until the open timeout has passed
+	StateOpen
+	// StateHalfOpen lets a limited number of trial calls through to decide whether to close again
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// Outcome is how a call let through by a Breaker ended
+type Outcome int
+
+const (
+	// OutcomeSuccess resets the failure count, or counts towards closing a half-open breaker
+	OutcomeSuccess Outcome = iota
+	// OutcomeFailure counts towards opening the breaker
+	OutcomeFailure
+	// OutcomeIgnored only gives back the call's half-open slot, for calls which say nothing about the service
+	OutcomeIgnored
+)
+
+// ErrOpen is returned by Breaker.Allow while the breaker rejects calls
+var ErrOpen = errors.New("circuit breaker is open")
+
+// BreakerOptions configures a Breaker
+type BreakerOptions struct {
+	FailureThreshold int           // consecutive failures which open the breaker
+	OpenTimeout      time.Duration // how long the breaker stays open before trying again
+	HalfOpenRequests int           // trial calls let through, and successes needed, to close again
+
+	// OnStateChange is called after the breaker's lock is released, so it may call back into the breaker
+	OnStateChange func(name string, from, to State)
+}
+
+// Breaker is a consecutive-failures circuit breaker
+type Breaker struct {
+	name string
+	opts BreakerOptions
+	now  func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64 // bumped on every transition so results of calls from an earlier state are ignored
+	failures   int
+	successes  int
+	inFlight   int
+	openedAt   time.Time
+	changes    []stateChange // transitions not yet reported to OnStateChange
+}
+
+type stateChange struct {
+	from, to State
+}
+
+// NewBreaker returns a closed Breaker reporting transitions under name
+func NewBreaker(name string, opts BreakerOptions) *Breaker {
+	if opts.HalfOpenRequests < 1 {
+		opts.HalfOpenRequests = 1
+	}
+
+	return &Breaker{
+		name: name,
+		opts: opts,
+		now:  time.Now,
+	}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.refresh(b.now())
+	return b.state
+}
+
+// Allow reports whether a call may go ahead. When it may, done must be called with how the call ended.
+func (b *Breaker) Allow() (done func(Outcome), err error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.refresh(b.now())
+	switch b.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.inFlight >= b.opts.HalfOpenRequests {
+			return nil, ErrOpen
+		}
+		b.inFlight++
+	}
+
+	generation := b.generation
+	return func(outcome Outcome) {
+		b.done(generation, outcome)
+	}, nil
+}
+
+func (b *Breaker) done(generation uint64, outcome Outcome) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case StateClosed:
+		switch outcome {
+		case OutcomeIgnored:
+			return
+		case OutcomeSuccess:
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.opts.FailureThreshold {
+			b.transition(StateOpen)
+		}
+	case StateHalfOpen:
+		b.inFlight--
+		switch outcome {
+		case OutcomeIgnored:
+			return
+		case OutcomeFailure:
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.opts.HalfOpenRequests {
+			b.transition(StateClosed)
+		}
+	}
+}
+
+// refresh moves an open breaker to half-open once its timeout has passed
+func (b *Breaker) refresh(now time.Time) {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.opts.OpenTimeout {
+		b.transition(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) transition(to State) {
+	from := b.state
+
+	b.state = to
+	b.generation++
+	b.failures, b.successes, b.inFlight = 0, 0, 0
+	if to == StateOpen {
+		b.openedAt = b.now()
+	}
+
+	if b.opts.OnStateChange != nil {
+		b.changes = append(b.changes, stateChange{from: from, to: to})
+	}
+}
+
+// unlock releases b.mu and then reports the transitions made while it was held
+func (b *Breaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	for _, change := range changes {
+		b.opts.OnStateChange(b.name, change.from, change.to)
+	}
+}
@@ -0,0 +1,241 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	opts := BreakerOptions{FailureThreshold: 3, OpenTimeout: 10 * time.Second, HalfOpenRequests: 2}
+
+	type step struct {
+		advance   time.Duration
+		wantOpen  bool // Allow is expected to return ErrOpen
+		outcome   Outcome
+		wantState State
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "failures below the threshold keep it closed",
+			steps: []step{
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+			},
+		},
+		{
+			name: "a success resets the failure count",
+			steps: []step{
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeSuccess, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+			},
+		},
+		{
+			name: "ignored calls neither reset nor count",
+			steps: []step{
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeIgnored, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateOpen},
+			},
+		},
+		{
+			name: "open rejects until the timeout passes",
+			steps: []step{
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateOpen},
+				{wantOpen: true, wantState: StateOpen},
+				{advance: 9 * time.Second, wantOpen: true, wantState: StateOpen},
+				{advance: time.Second, outcome: OutcomeSuccess, wantState: StateHalfOpen},
+			},
+		},
+		{
+			name: "enough half-open successes close it",
+			steps: []step{
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateOpen},
+				{advance: 10 * time.Second, outcome: OutcomeSuccess, wantState: StateHalfOpen},
+				{outcome: OutcomeSuccess, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+			},
+		},
+		{
+			name: "a half-open failure opens it again",
+			steps: []step{
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateOpen},
+				{advance: 10 * time.Second, outcome: OutcomeSuccess, wantState: StateHalfOpen},
+				{outcome: OutcomeFailure, wantState: StateOpen},
+				{advance: 5 * time.Second, wantOpen: true, wantState: StateOpen},
+			},
+		},
+		{
+			name: "ignored half-open calls do not count towards closing",
+			steps: []step{
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateClosed},
+				{outcome: OutcomeFailure, wantState: StateOpen},
+				{advance: 10 * time.Second, outcome: OutcomeIgnored, wantState: StateHalfOpen},
+				{outcome: OutcomeSuccess, wantState: StateHalfOpen},
+				{outcome: OutcomeIgnored, wantState: StateHalfOpen},
+				{outcome: OutcomeSuccess, wantState: StateClosed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+			b := NewBreaker("test", opts)
+			b.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+
+				done, err := b.Allow()
+				if s.wantOpen {
+					if !errors.Is(err, ErrOpen) {
+						t.Fatalf("step %d: Allow() error = %v, want ErrOpen", i, err)
+					}
+				} else {
+					if err != nil {
+						t.Fatalf("step %d: Allow() error = %v", i, err)
+					}
+					done(s.outcome)
+				}
+
+				if got := b.State(); got != s.wantState {
+					t.Fatalf("step %d: State() = %s, want %s", i, got, s.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestBreakerLimitsHalfOpenCalls(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := NewBreaker("test", BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenRequests: 2})
+	b.now = func() time.Time { return now }
+
+	done, _ := b.Allow()
+	done(OutcomeFailure)
+	now = now.Add(time.Second)
+
+	first, err := b.Allow()
+	if err != nil {
+		t.Fatalf("first half-open Allow() error = %v", err)
+	}
+	if _, err := b.Allow(); err != nil {
+		t.Fatalf("second half-open Allow() error = %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() past the half-open limit error = %v, want ErrOpen", err)
+	}
+
+	first(OutcomeIgnored)
+	if _, err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after an ignored call gave back its slot error = %v", err)
+	}
+}
+
+func TestBreakerIgnoresCallsFromAnEarlierState(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := NewBreaker("test", BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenRequests: 1})
+	b.now = func() time.Time { return now }
+
+	slow, _ := b.Allow()
+	done, _ := b.Allow()
+	done(OutcomeFailure)
+	now = now.Add(time.Second)
+
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("State() = %s, want %s", got, StateHalfOpen)
+	}
+
+	// the slow call was let through while closed, so its success must not close the half-open breaker
+	slow(OutcomeSuccess)
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("State() after a stale success = %s, want %s", got, StateHalfOpen)
+	}
+}
+
+func TestBreakerReportsStateChanges(t *testing.T) {
+	type change struct {
+		name     string
+		from, to State
+	}
+	var changes []change
+
+	now := time.Unix(1700000000, 0)
+	b := NewBreaker("test", BreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Second,
+		HalfOpenRequests: 1,
+		OnStateChange: func(name string, from, to State) {
+			changes = append(changes, change{name: name, from: from, to: to})
+		},
+	})
+	b.now = func() time.Time { return now }
+
+	done, _ := b.Allow()
+	done(OutcomeFailure)
+	now = now.Add(time.Second)
+	done, _ = b.Allow()
+	done(OutcomeSuccess)
+
+	want := []change{
+		{name: "test", from: StateClosed, to: StateOpen},
+		{name: "test", from: StateOpen, to: StateHalfOpen},
+		{name: "test", from: StateHalfOpen, to: StateClosed},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("state changes = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("state change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestBreakerStateChangeCanCallBack(t *testing.T) {
+	var b *Breaker
+	var states []State
+	b = NewBreaker("test", BreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 1,
+		OnStateChange: func(name string, from, to State) {
+			states = append(states, b.State())
+			if _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+				t.Errorf("Allow() from OnStateChange error = %v, want ErrOpen", err)
+			}
+		},
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		done, _ := b.Allow()
+		done(OutcomeFailure)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatalf("OnStateChange calling back into the breaker deadlocked")
+	}
+	if len(states) != 1 || states[0] != StateOpen {
+		t.Fatalf("states seen by OnStateChange = %v, want [%s]", states, StateOpen)
+	}
+}
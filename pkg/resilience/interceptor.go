@@ -0,0 +1,168 @@
+package resilience
+
+import (
+	"book-api-gateway/pkg/logger"
+	"context"
+	"errors"
+	"expvar"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// metrics is published as "upstream" on /debug/vars style expvar handlers, keyed by "<service>.<metric>"
+var metrics = expvar.NewMap("upstream")
+
+// failureCodes are the status codes which count against a service's breaker.
+// Errors caused by the request itself, like NotFound or InvalidArgument, say nothing about the service's health.
+var failureCodes = map[codes.Code]bool{
+	codes.Unknown:           true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+	codes.Internal:          true,
+	codes.Unavailable:       true,
+	codes.DataLoss:          true,
+}
+
+// Options configures UnaryClientInterceptor
+type Options struct {
+	Services          []string // full grpc service names to protect, others pass through untouched
+	IdempotentMethods []string // method names which may be retried, e.g. "GetAll"
+	Retry             RetryPolicy
+	Breaker           BreakerOptions
+	Log               logger.Logger
+}
+
+type interceptor struct {
+	opts       Options
+	services   map[string]bool
+	idempotent map[string]bool
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// UnaryClientInterceptor retries idempotent calls and guards every protected service with its own circuit breaker.
+// Calls rejected by an open breaker fail fast with codes.Unavailable.
+func UnaryClientInterceptor(opts Options) grpc.UnaryClientInterceptor {
+	i := &interceptor{
+		opts:       opts,
+		services:   map[string]bool{},
+		idempotent: map[string]bool{},
+		breakers:   map[string]*Breaker{},
+	}
+	for _, service := range opts.Services {
+		i.services[service] = true
+	}
+	for _, method := range opts.IdempotentMethods {
+		i.idempotent[method] = true
+	}
+
+	onStateChange := opts.Breaker.OnStateChange
+	i.opts.Breaker.OnStateChange = func(name string, from, to State) {
+		i.opts.Log.Warn("circuit breaker state changed",
+			logger.String("service", name),
+			logger.String("from", from.String()),
+			logger.String("to", to.String()),
+		)
+		metrics.Add(name+".transitions", 1)
+		metrics.Set(name+".state", stateVar(to))
+		if onStateChange != nil {
+			onStateChange(name, from, to)
+		}
+	}
+
+	return i.intercept
+}
+
+func (i *interceptor) intercept(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+	service, name := splitMethod(method)
+	if !i.services[service] {
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+
+	breaker := i.breaker(service)
+	maxAttempts := 1
+	if i.idempotent[name] && i.opts.Retry.MaxAttempts > 1 {
+		maxAttempts = i.opts.Retry.MaxAttempts
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		done, openErr := breaker.Allow()
+		if openErr != nil {
+			metrics.Add(service+".rejected", 1)
+			return status.Errorf(codes.Unavailable, "%s is unavailable: %v", service, openErr)
+		}
+
+		err = invoker(ctx, method, req, reply, cc, callOpts...)
+		done(outcome(ctx, err))
+
+		if err == nil || attempt >= maxAttempts || !i.opts.Retry.retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(i.opts.Retry.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		metrics.Add(service+".retries", 1)
+		i.opts.Log.Debug("retrying upstream call",
+			logger.String("method", method),
+			logger.Int("attempt", attempt+1),
+			logger.Error(err),
+		)
+	}
+}
+
+// outcome classifies a finished attempt for the breaker. ctx is the caller's context, so a DeadlineExceeded
+// from the upstream's own per-attempt timeout, or from the caller's deadline, counts as a failure.
+// An attempt cut short by the caller canceling says nothing about the service either way.
+func outcome(ctx context.Context, err error) Outcome {
+	code := status.Code(err)
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(ctx.Err(), context.Canceled) || code == codes.Canceled:
+		return OutcomeIgnored
+	case failureCodes[code]:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// breaker returns the breaker of service, creating a closed one on first use
+func (i *interceptor) breaker(service string) *Breaker {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	b, ok := i.breakers[service]
+	if !ok {
+		b = NewBreaker(service, i.opts.Breaker)
+		i.breakers[service] = b
+		metrics.Set(service+".state", stateVar(StateClosed))
+	}
+
+	return b
+}
+
+// splitMethod splits "/package.Service/Method" into its service and method names
+func splitMethod(fullMethod string) (service, method string) {
+	service, method, _ = strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service, method
+}
+
+func stateVar(s State) *expvar.String {
+	v := new(expvar.String)
+	v.Set(s.String())
+	return v
+}
@@ -0,0 +1,49 @@
+package resilience
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	jitterMu sync.Mutex
+	jitter   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// RetryPolicy retries failed calls with capped exponential backoff and full jitter
+type RetryPolicy struct {
+	MaxAttempts    int // including the first call, 1 disables retries
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Codes          []codes.Code // status codes worth retrying
+}
+
+// retryable reports whether a call which failed with err may be tried again
+func (p RetryPolicy) retryable(err error) bool {
+	code := status.Code(err)
+	for _, c := range p.Codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns a random wait before retry n (starting at 1), up to the exponential backoff for n
+func (p RetryPolicy) backoff(n int) time.Duration {
+	ceiling := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(n-1))
+	if ceiling > float64(p.MaxBackoff) {
+		ceiling = float64(p.MaxBackoff)
+	}
+
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitter.Int63n(int64(ceiling) + 1))
+}
@@ -0,0 +1,177 @@
+package tabular
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// CSV is comma separated values with a header row naming the columns
+	CSV = "csv"
+	// JSONL is JSON Lines, one JSON object per line
+	JSONL = "jsonl"
+	// XLSX is an Office Open XML spreadsheet, supported for writing only
+	XLSX = "xlsx"
+)
+
+// ErrUnsupportedFormat is returned for formats a reader or writer cannot handle
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// Row is a record keyed by column name
+type Row map[string]string
+
+// RowError is a single malformed row. Readers can keep reading after it.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader streams rows from an underlying io.Reader without loading it whole
+type Reader interface {
+	// Read returns the next row, a *RowError for a malformed row, or io.EOF after the last one
+	Read() (Row, error)
+	// Line is the line of the row last returned by Read
+	Line() int
+}
+
+// NewReader returns a Reader of format over r
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case CSV:
+		return newCSVReader(r), nil
+	case JSONL:
+		return &jsonlReader{r: bufio.NewReader(r)}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// FormatFromFilename guesses the format from the file extension, "" when unknown
+func FormatFromFilename(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return CSV
+	case ".jsonl", ".ndjson":
+		return JSONL
+	case ".xlsx":
+		return XLSX
+	default:
+		return ""
+	}
+}
+
+type csvReader struct {
+	r      *csv.Reader
+	header []string
+	line   int
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	return &csvReader{r: cr}
+}
+
+func (r *csvReader) Read() (Row, error) {
+	if r.header == nil {
+		header, err := r.r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+
+		r.header = make([]string, len(header))
+		for i, column := range header {
+			r.header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		}
+	}
+
+	record, err := r.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			r.line = parseErr.StartLine
+			return nil, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return nil, err
+	}
+	r.line, _ = r.r.FieldPos(0)
+
+	row := make(Row, len(r.header))
+	for i, column := range r.header {
+		if i < len(record) {
+			row[column] = strings.TrimSpace(record[i])
+		}
+	}
+
+	return row, nil
+}
+
+func (r *csvReader) Line() int {
+	return r.line
+}
+
+type jsonlReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (r *jsonlReader) Read() (Row, error) {
+	for {
+		b, err := r.r.ReadBytes('\n')
+		if len(b) == 0 && err != nil {
+			return nil, err
+		}
+		r.line++
+
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var fields map[string]interface{}
+		if jsonErr := json.Unmarshal(b, &fields); jsonErr != nil {
+			return nil, &RowError{Line: r.line, Err: jsonErr}
+		}
+
+		row := make(Row, len(fields))
+		for key, value := range fields {
+			switch v := value.(type) {
+			case nil:
+				row[strings.ToLower(key)] = ""
+			case string:
+				row[strings.ToLower(key)] = strings.TrimSpace(v)
+			default:
+				row[strings.ToLower(key)] = fmt.Sprint(v)
+			}
+		}
+
+		return row, nil
+	}
+}
+
+func (r *jsonlReader) Line() int {
+	return r.line
+}
@@ -0,0 +1,155 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// maxXLSXRows is the row limit of a spreadsheet, header included
+const maxXLSXRows = 1048576
+
+// ErrTooManyRows is returned when rows no longer fit into an XLSX sheet
+var ErrTooManyRows = errors.New("too many rows for a spreadsheet")
+
+// Writer writes rows of fixed columns, the header first
+type Writer interface {
+	Write(values []string) error
+	// Flush pushes buffered rows to the underlying io.Writer where the format allows it
+	Flush() error
+	// Close writes whatever the format needs after the last row. It does not close the io.Writer.
+	Close() error
+}
+
+// NewWriter returns a Writer of format over w, with columns as the header
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case CSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(columns)
+	case JSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w), columns: columns}, nil
+	case XLSX:
+		return newXLSXWriter(w, columns)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// ContentType is the media type of format
+func ContentType(format string) string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case JSONL:
+		return "application/x-ndjson"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvWriter) Write(values []string) error {
+	return w.w.Write(values)
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) Close() error {
+	return w.Flush()
+}
+
+type jsonlWriter struct {
+	enc     *json.Encoder
+	columns []string
+}
+
+func (w *jsonlWriter) Write(values []string) error {
+	row := make(map[string]string, len(w.columns))
+	for i, column := range w.columns {
+		if i < len(values) {
+			row[column] = values[i]
+		}
+	}
+
+	return w.enc.Encode(row)
+}
+
+func (w *jsonlWriter) Flush() error {
+	return nil
+}
+
+func (w *jsonlWriter) Close() error {
+	return nil
+}
+
+// xlsxWriter streams rows into a single sheet. A workbook is a zip archive that can only be written
+// once complete, so rows are spilled to a temporary file by excelize and w receives it on Close.
+type xlsxWriter struct {
+	w    io.Writer
+	file *excelize.File
+	sw   *excelize.StreamWriter
+	row  int
+}
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+
+	sw, err := file.NewStreamWriter(file.GetSheetName(0))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	xw := &xlsxWriter{w: w, file: file, sw: sw}
+	if err := xw.Write(columns); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return xw, nil
+}
+
+func (w *xlsxWriter) Write(values []string) error {
+	if w.row >= maxXLSXRows {
+		return ErrTooManyRows
+	}
+	w.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		row[i] = value
+	}
+
+	return w.sw.SetRow(cell, row)
+}
+
+func (w *xlsxWriter) Flush() error {
+	return nil
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.sw.Flush(); err != nil {
+		return err
+	}
+
+	return w.file.Write(w.w)
+}
@@ -1,9 +1,8 @@
 package util
 
-import "regexp"
+import "book-api-gateway/pkg/uuid"
 
-// IsValidUUID ...
-func IsValidUUID(uuid string) bool {
-	r := regexp.MustCompile("^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-4[a-fA-F0-9]{3}-[8|9|aA|bB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}$")
-	return r.MatchString(uuid)
+// IsValidUUID reports whether id is a canonical RFC 4122 UUID of any version
+func IsValidUUID(id string) bool {
+	return uuid.Validate(id) == nil
 }
@@ -0,0 +1,133 @@
+package uuid
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// UUID is an RFC 4122 / RFC 9562 universally unique identifier
+type UUID [16]byte
+
+var (
+	// Nil is the all-zero UUID
+	Nil UUID
+
+	// ErrInvalidFormat ...
+	ErrInvalidFormat = errors.New("uuid must be in the form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx")
+	// ErrInvalidVariant ...
+	ErrInvalidVariant = errors.New("uuid must have the RFC 4122 variant")
+)
+
+// hyphens holds the positions of the dashes in the canonical 36 character form
+var hyphens = [...]int{8, 13, 18, 23}
+
+// Parse decodes the canonical 8-4-4-4-12 hex form in any letter case
+func Parse(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 {
+		return u, ErrInvalidFormat
+	}
+	for _, i := range hyphens {
+		if s[i] != '-' {
+			return u, ErrInvalidFormat
+		}
+	}
+
+	src := []byte(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36])
+	if _, err := hex.Decode(u[:], src); err != nil {
+		return Nil, ErrInvalidFormat
+	}
+
+	return u, nil
+}
+
+// MustParse is like Parse but panics on invalid input
+func MustParse(s string) UUID {
+	u, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return u
+}
+
+// Validate parses s and checks it has the RFC 4122 variant and one of versions (any of 1-8 when none given)
+func Validate(s string, versions ...int) error {
+	u, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	return u.Check(versions...)
+}
+
+// Check reports whether u has the RFC 4122 variant and one of versions (any of 1-8 when none given)
+func (u UUID) Check(versions ...int) error {
+	if u[8]&0xc0 != 0x80 {
+		return ErrInvalidVariant
+	}
+
+	version := u.Version()
+	if len(versions) == 0 {
+		if version < 1 || version > 8 {
+			return fmt.Errorf("uuid version %d is not supported", version)
+		}
+		return nil
+	}
+
+	for _, allowed := range versions {
+		if version == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("uuid version %d is not allowed", version)
+}
+
+// Version returns the version nibble
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// String returns the canonical lower case form
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf[:])
+}
+
+// Canonicalize parses s and returns it in the canonical lower case form
+func Canonicalize(s string) (string, error) {
+	u, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*u = parsed
+	return nil
+}
@@ -0,0 +1,101 @@
+package uuid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr error
+	}{
+		{value: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", want: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{value: "6BA7B810-9DAD-11D1-80B4-00C04FD430C8", want: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{value: "00000000-0000-0000-0000-000000000000", want: "00000000-0000-0000-0000-000000000000"},
+		{value: "", wantErr: ErrInvalidFormat},
+		{value: "6ba7b8109dad11d180b400c04fd430c8", wantErr: ErrInvalidFormat},
+		{value: "{6ba7b810-9dad-11d1-80b4-00c04fd430c8}", wantErr: ErrInvalidFormat},
+		{value: "6ba7b810-9dad-11d1-80b4-00c04fd430c", wantErr: ErrInvalidFormat},
+		{value: "6ba7b8109-dad-11d1-80b4-00c04fd430c8", wantErr: ErrInvalidFormat},
+		{value: "6ba7b810-9dad-11d1-80b4-00c04fd430cg", wantErr: ErrInvalidFormat},
+		{value: "6ba7b810-9dad-11d1-80b4+00c04fd430c8", wantErr: ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if got != Nil {
+					t.Fatalf("Parse(%q) = %s, want Nil", tt.value, got)
+				}
+				return
+			}
+			if got.String() != tt.want {
+				t.Fatalf("Parse(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const (
+		v1 = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+		v4 = "3f2b0c8e-9a41-4c3b-8f1e-2d7c6b5a4e31"
+		v7 = "01890a5d-ac96-774b-bcce-b302099a8057"
+	)
+
+	tests := []struct {
+		name     string
+		value    string
+		versions []int
+		wantErr  bool
+	}{
+		{name: "v1 with any version", value: v1},
+		{name: "v4 with any version", value: v4},
+		{name: "v7 with any version", value: v7},
+		{name: "v4 allowed", value: v4, versions: []int{4}},
+		{name: "v7 among allowed", value: v7, versions: []int{4, 7}},
+		{name: "v1 not allowed", value: v1, versions: []int{4, 7}, wantErr: true},
+		{name: "nil uuid has no variant", value: "00000000-0000-0000-0000-000000000000", wantErr: true},
+		{name: "version 0", value: "3f2b0c8e-9a41-0c3b-8f1e-2d7c6b5a4e31", wantErr: true},
+		{name: "version 9", value: "3f2b0c8e-9a41-9c3b-8f1e-2d7c6b5a4e31", wantErr: true},
+		{name: "microsoft variant", value: "3f2b0c8e-9a41-4c3b-cf1e-2d7c6b5a4e31", wantErr: true},
+		{name: "ncs variant", value: "3f2b0c8e-9a41-4c3b-0f1e-2d7c6b5a4e31", wantErr: true},
+		{name: "malformed", value: "not-a-uuid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.value, tt.versions...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate(%q, %v) error = %v, wantErr %v", tt.value, tt.versions, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	want := MustParse("3f2b0c8e-9a41-4c3b-8f1e-2d7c6b5a4e31")
+
+	text, err := want.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+
+	var got UUID
+	if err := got.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText(%q) error = %v", text, err)
+	}
+	if got != want {
+		t.Fatalf("UnmarshalText(%q) = %s, want %s", text, got, want)
+	}
+
+	if err := got.UnmarshalText([]byte("not-a-uuid")); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("UnmarshalText() error = %v, want %v", err, ErrInvalidFormat)
+	}
+}
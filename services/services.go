@@ -3,34 +3,110 @@ package services
 import (
 	"book-api-gateway/config"
 	"book-api-gateway/genproto/book_service"
+	"book-api-gateway/pkg/lb"
+	"book-api-gateway/pkg/logger"
+	"book-api-gateway/pkg/resilience"
+	"context"
 	"fmt"
+	"strconv"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 type ServicesI interface {
 	BookCategoryService() book_service.BookCategoryServiceClient
 	BookService() book_service.BookServiceClient
+	HealthCheck(ctx context.Context) error
+	Backends() []lb.Backend
+	Close() error
 }
 
 type servicesRepo struct {
+	upstreams []*upstream
+
 	bookCategoryService book_service.BookCategoryServiceClient
 	bookService         book_service.BookServiceClient
 }
 
-func NewServicesRepo(c *config.Config) (ServicesI, error) {
-	connBookCategoryService, err := grpc.Dial(
-		fmt.Sprintf("%s:%d", c.BookServiceHost, c.BookServicePort),
-		grpc.WithInsecure())
-	if err != nil {
-		return nil, err
+// upstreamClients are the grpc services an upstream serves and the clients built on its connection
+type upstreamClients struct {
+	services []string // full grpc service names, each guarded by its own circuit breaker
+	build    func(s *servicesRepo, conn *grpc.ClientConn)
+}
+
+// clients is the registry of the service clients of every upstream in config.UpstreamNames
+var clients = map[string]upstreamClients{
+	config.UpstreamBook: {
+		services: []string{book_service.BookService_ServiceDesc.ServiceName},
+		build: func(s *servicesRepo, conn *grpc.ClientConn) {
+			s.bookService = book_service.NewBookServiceClient(conn)
+		},
+	},
+	config.UpstreamBookCategory: {
+		services: []string{book_service.BookCategoryService_ServiceDesc.ServiceName},
+		build: func(s *servicesRepo, conn *grpc.ClientConn) {
+			s.bookCategoryService = book_service.NewBookCategoryServiceClient(conn)
+		},
+	},
+}
+
+// NewServicesRepo opens one connection per upstream in config.Upstreams and builds the service clients on them
+func NewServicesRepo(c *config.Config, log logger.Logger) (ServicesI, error) {
+	for name := range clients {
+		if _, ok := c.Upstreams[name]; !ok {
+			return nil, fmt.Errorf("%s service is not configured", name)
+		}
 	}
 
-	return &servicesRepo{
-		bookCategoryService: book_service.NewBookCategoryServiceClient(connBookCategoryService),
-		bookService:         book_service.NewBookServiceClient(connBookCategoryService),
-	}, nil
+	s := &servicesRepo{}
+	for _, name := range config.UpstreamNames {
+		upstreamClients, ok := clients[name]
+		if !ok {
+			_ = s.Close()
+			return nil, fmt.Errorf("%s service has no clients", name)
+		}
+
+		u, err := dialUpstream(name, c.Upstreams[name], c, log, resilienceInterceptor(c, log, upstreamClients.services))
+		if err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+		s.upstreams = append(s.upstreams, u)
+
+		upstreamClients.build(s, u.conn)
+	}
+
+	return s, nil
+}
+
+// resilienceInterceptor retries reads and guards each of services with its own circuit breaker
+func resilienceInterceptor(c *config.Config, log logger.Logger, services []string) grpc.UnaryClientInterceptor {
+	retryCodes := make([]codes.Code, 0, len(c.RetryCodes))
+	for _, name := range c.RetryCodes {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(name))); err == nil {
+			retryCodes = append(retryCodes, code)
+		}
+	}
 
+	return resilience.UnaryClientInterceptor(resilience.Options{
+		Services:          services,
+		IdempotentMethods: []string{"GetAll", "GetById"},
+		Retry: resilience.RetryPolicy{
+			MaxAttempts:    c.RetryMaxAttempts,
+			InitialBackoff: c.RetryInitialBackoff,
+			MaxBackoff:     c.RetryMaxBackoff,
+			Multiplier:     c.RetryBackoffMultiplier,
+			Codes:          retryCodes,
+		},
+		Breaker: resilience.BreakerOptions{
+			FailureThreshold: c.BreakerFailureThreshold,
+			OpenTimeout:      c.BreakerOpenTimeout,
+			HalfOpenRequests: c.BreakerHalfOpenRequests,
+		},
+		Log: log,
+	})
 }
 
 func (s *servicesRepo) BookCategoryService() book_service.BookCategoryServiceClient {
@@ -39,3 +115,31 @@ func (s *servicesRepo) BookCategoryService() book_service.BookCategoryServiceCli
 func (s *servicesRepo) BookService() book_service.BookServiceClient {
 	return s.bookService
 }
+
+// HealthCheck fails when any upstream connection is down or its service does not report SERVING
+func (s *servicesRepo) HealthCheck(ctx context.Context) error {
+	for _, u := range s.upstreams {
+		if err := u.healthCheck(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Backends returns the address and connectivity state of every upstream replica
+func (s *servicesRepo) Backends() []lb.Backend {
+	return lb.Backends()
+}
+
+// Close closes every upstream connection
+func (s *servicesRepo) Close() error {
+	var firstErr error
+	for _, u := range s.upstreams {
+		if err := u.conn.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("closing %s service connection: %w", u.name, err)
+		}
+	}
+
+	return firstErr
+}
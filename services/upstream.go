@@ -0,0 +1,161 @@
+package services
+
+import (
+	"book-api-gateway/config"
+	"book-api-gateway/pkg/certs"
+	"book-api-gateway/pkg/lb"
+	"book-api-gateway/pkg/logger"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// upstream is the managed connection to one service from config.Upstreams
+type upstream struct {
+	name string
+	cfg  config.Upstream
+	conn *grpc.ClientConn
+}
+
+// dialUpstream connects to the upstream called name. interceptors run around the upstream's own timeout,
+// so a retrying interceptor gets a fresh timeout for every attempt.
+func dialUpstream(name string, u config.Upstream, c *config.Config, log logger.Logger, interceptors ...grpc.UnaryClientInterceptor) (*upstream, error) {
+	creds, err := transportCredentials(u, c.TLSReloadInterval, log)
+	if err != nil {
+		return nil, fmt.Errorf("%s service tls: %w", name, err)
+	}
+
+	target, opts := upstreamTarget(name, u)
+	opts = append(opts,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(lb.ServiceConfig(name, u.LBPolicy, u.HealthCheck, u.HealthName)),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(u.MaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(u.MaxSendMsgSize),
+		),
+		grpc.WithChainUnaryInterceptor(append(interceptors[:len(interceptors):len(interceptors)], timeoutInterceptor(u.Timeout))...),
+	)
+	if u.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    u.KeepaliveTime,
+			Timeout: u.KeepaliveTimeout,
+		}))
+	}
+
+	ctx := context.Background()
+	if u.DialBlock {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.DialTimeout)
+		defer cancel()
+		opts = append(opts, grpc.WithBlock())
+	}
+
+	conn, err := grpc.DialContext(ctx, target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dialing %s service: %w", name, err)
+	}
+
+	return &upstream{
+		name: name,
+		cfg:  u,
+		conn: conn,
+	}, nil
+}
+
+// upstreamTarget returns the dial target of an upstream: its addrs as a static list of replicas
+// or a dns name, falling back to host:port
+func upstreamTarget(name string, u config.Upstream) (string, []grpc.DialOption) {
+	switch {
+	case len(u.Addrs) == 1 && strings.HasPrefix(u.Addrs[0], "dns:"):
+		return u.Addrs[0], nil
+	case len(u.Addrs) > 0:
+		addresses := make([]resolver.Address, 0, len(u.Addrs))
+		for _, addr := range u.Addrs {
+			addresses = append(addresses, resolver.Address{Addr: addr})
+		}
+
+		r := manual.NewBuilderWithScheme("static")
+		r.InitialState(resolver.State{Addresses: addresses})
+		return "static:///" + name, []grpc.DialOption{grpc.WithResolvers(r)}
+	default:
+		return fmt.Sprintf("%s:%d", u.Host, u.Port), nil
+	}
+}
+
+// transportCredentials verifies the upstream against the system roots or its CA bundle and,
+// when a client certificate is configured, presents it for mTLS, reloading it as it is rotated
+func transportCredentials(u config.Upstream, reloadInterval time.Duration, log logger.Logger) (credentials.TransportCredentials, error) {
+	if !u.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: u.TLSServerName,
+	}
+
+	if u.TLSCAFile != "" {
+		pool, err := certs.LoadCertPool(u.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if u.TLSCertFile != "" {
+		reloader, err := certs.NewReloader(u.TLSCertFile, u.TLSKeyFile, reloadInterval, log)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.GetClientCertificate
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// timeoutInterceptor bounds every call by timeout unless the caller's deadline is sooner
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if timeout <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// healthCheck fails when the connection is down or the upstream does not report SERVING
+func (u *upstream) healthCheck(ctx context.Context) error {
+	state := u.conn.GetState()
+	switch state {
+	case connectivity.Idle:
+		u.conn.Connect()
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return fmt.Errorf("%s service connection is %s", u.name, state)
+	}
+
+	resp, err := healthpb.NewHealthClient(u.conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: u.cfg.HealthName,
+	})
+	if err != nil {
+		return fmt.Errorf("%s service health check: %w", u.name, err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%s service is %s", u.name, resp.GetStatus())
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package file
+
+import (
+	"book-api-gateway/storage"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type apiKeyRepo struct {
+	mu   sync.RWMutex
+	dir  string
+	keys map[string]*storage.ApiKey
+}
+
+// NewApiKeyRepo returns a storage.ApiKeyRepoI keeping every api key as a JSON file in dir, so keys survive restarts.
+// The keys in dir are loaded once, later reads are served from memory.
+func NewApiKeyRepo(dir string) (storage.ApiKeyRepoI, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &apiKeyRepo{
+		dir:  dir,
+		keys: map[string]*storage.ApiKey{},
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var key storage.ApiKey
+		if err := json.Unmarshal(b, &key); err != nil {
+			return nil, err
+		}
+		r.keys[key.Id] = &key
+	}
+
+	return r, nil
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *storage.ApiKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.dir, key.Id+".json")
+	if err := os.WriteFile(path+".tmp", b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	created := *key
+	r.keys[key.Id] = &created
+	return nil
+}
+
+func (r *apiKeyRepo) GetById(ctx context.Context, id string) (*storage.ApiKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	found := *key
+	return &found, nil
+}
+
+func (r *apiKeyRepo) GetAll(ctx context.Context) ([]*storage.ApiKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*storage.ApiKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		found := *key
+		keys = append(keys, &found)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (r *apiKeyRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	if err := os.Remove(filepath.Join(r.dir, id+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	delete(r.keys, id)
+	return nil
+}
@@ -0,0 +1,134 @@
+package file
+
+import (
+	"book-api-gateway/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type jobRepo struct {
+	mu   sync.RWMutex
+	dir  string
+	jobs map[string]*storage.Job
+}
+
+// NewJobRepo returns a storage.JobRepoI keeping every job as a JSON file in dir, so jobs survive restarts.
+// The jobs in dir are loaded once, later reads are served from memory.
+func NewJobRepo(dir string) (storage.JobRepoI, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &jobRepo{
+		dir:  dir,
+		jobs: map[string]*storage.Job{},
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var job storage.Job
+		if err := json.Unmarshal(b, &job); err != nil {
+			return nil, err
+		}
+		r.jobs[job.Id] = &job
+	}
+
+	return r, nil
+}
+
+func (r *jobRepo) Create(ctx context.Context, job *storage.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.save(job)
+}
+
+func (r *jobRepo) GetById(ctx context.Context, id string) (*storage.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	found := *job
+	return &found, nil
+}
+
+func (r *jobRepo) GetAll(ctx context.Context) ([]*storage.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := make([]*storage.Job, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		found := *job
+		jobs = append(jobs, &found)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+
+	return jobs, nil
+}
+
+func (r *jobRepo) Update(ctx context.Context, job *storage.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.Id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	return r.save(job)
+}
+
+func (r *jobRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.Remove(filepath.Join(r.dir, id+".json")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	delete(r.jobs, id)
+	return nil
+}
+
+// save writes job to a temporary file renamed over the previous version, so a crash never leaves half a job behind
+func (r *jobRepo) save(job *storage.Job) error {
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.dir, job.Id+".json")
+	if err := os.WriteFile(path+".tmp", b, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	saved := *job
+	r.jobs[job.Id] = &saved
+	return nil
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"book-api-gateway/storage"
+	"context"
+	"sort"
+	"sync"
+)
+
+type apiKeyRepo struct {
+	mu   sync.RWMutex
+	keys map[string]*storage.ApiKey
+}
+
+// NewApiKeyRepo returns a storage.ApiKeyRepoI keeping keys in process memory, they are lost on restart
+func NewApiKeyRepo() storage.ApiKeyRepoI {
+	return &apiKeyRepo{
+		keys: map[string]*storage.ApiKey{},
+	}
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *storage.ApiKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := *key
+	r.keys[key.Id] = &created
+	return nil
+}
+
+func (r *apiKeyRepo) GetById(ctx context.Context, id string) (*storage.ApiKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	found := *key
+	return &found, nil
+}
+
+func (r *apiKeyRepo) GetAll(ctx context.Context) ([]*storage.ApiKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*storage.ApiKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		found := *key
+		keys = append(keys, &found)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (r *apiKeyRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(r.keys, id)
+	return nil
+}
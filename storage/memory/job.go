@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"book-api-gateway/storage"
+	"context"
+	"sort"
+	"sync"
+)
+
+type jobRepo struct {
+	mu   sync.RWMutex
+	jobs map[string]*storage.Job
+}
+
+// NewJobRepo returns a storage.JobRepoI that forgets every job on restart
+func NewJobRepo() storage.JobRepoI {
+	return &jobRepo{
+		jobs: map[string]*storage.Job{},
+	}
+}
+
+func (r *jobRepo) Create(ctx context.Context, job *storage.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := *job
+	r.jobs[job.Id] = &created
+	return nil
+}
+
+func (r *jobRepo) GetById(ctx context.Context, id string) (*storage.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	found := *job
+	return &found, nil
+}
+
+func (r *jobRepo) GetAll(ctx context.Context) ([]*storage.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := make([]*storage.Job, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		found := *job
+		jobs = append(jobs, &found)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+
+	return jobs, nil
+}
+
+func (r *jobRepo) Update(ctx context.Context, job *storage.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.Id]; !ok {
+		return storage.ErrNotFound
+	}
+
+	updated := *job
+	r.jobs[job.Id] = &updated
+	return nil
+}
+
+func (r *jobRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.jobs, id)
+	return nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"book-api-gateway/storage"
+)
+
+type storageMemory struct {
+	user         *userRepo
+	revokedToken *revokedTokenRepo
+	otp          *otpRepo
+	apiKey       storage.ApiKeyRepoI
+}
+
+// NewStorage returns an in-process storage.StorageI, seeding users from usersFile when it is set.
+// apiKeys replaces the in-memory api key repo when it is not nil.
+func NewStorage(usersFile string, apiKeys storage.ApiKeyRepoI) (storage.StorageI, error) {
+	user, err := newUserRepo(usersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKeys == nil {
+		apiKeys = NewApiKeyRepo()
+	}
+
+	return &storageMemory{
+		user:         user,
+		revokedToken: newRevokedTokenRepo(),
+		otp:          newOTPRepo(),
+		apiKey:       apiKeys,
+	}, nil
+}
+
+func (s *storageMemory) User() storage.UserRepoI {
+	return s.user
+}
+
+func (s *storageMemory) RevokedToken() storage.RevokedTokenRepoI {
+	return s.revokedToken
+}
+
+func (s *storageMemory) OTP() storage.OTPRepoI {
+	return s.otp
+}
+
+func (s *storageMemory) ApiKey() storage.ApiKeyRepoI {
+	return s.apiKey
+}
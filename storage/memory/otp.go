@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"book-api-gateway/storage"
+	"context"
+	"sync"
+	"time"
+)
+
+type otpRepo struct {
+	mu   sync.Mutex
+	otps map[string]*storage.OTP
+}
+
+func newOTPRepo() *otpRepo {
+	return &otpRepo{
+		otps: map[string]*storage.OTP{},
+	}
+}
+
+func (r *otpRepo) Save(ctx context.Context, otp *storage.OTP, cooldown time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for recipient, o := range r.otps {
+		if o.ExpiresAt.Before(now) {
+			delete(r.otps, recipient)
+		}
+	}
+
+	if pending, ok := r.otps[otp.Recipient]; ok && now.Sub(pending.SentAt) < cooldown {
+		return storage.ErrCooldown
+	}
+
+	saved := *otp
+	r.otps[otp.Recipient] = &saved
+	return nil
+}
+
+func (r *otpRepo) Get(ctx context.Context, recipient string) (*storage.OTP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.otps[recipient]
+	if !ok || o.ExpiresAt.Before(time.Now()) {
+		return nil, storage.ErrNotFound
+	}
+
+	found := *o
+	return &found, nil
+}
+
+func (r *otpRepo) IncrementAttempts(ctx context.Context, recipient string) (*storage.OTP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.otps[recipient]
+	if !ok || o.ExpiresAt.Before(time.Now()) {
+		return nil, storage.ErrNotFound
+	}
+
+	o.Attempts++
+	found := *o
+	return &found, nil
+}
+
+func (r *otpRepo) Delete(ctx context.Context, recipient string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.otps, recipient)
+	return nil
+}
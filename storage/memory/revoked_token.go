@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type revokedTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time
+}
+
+func newRevokedTokenRepo() *revokedTokenRepo {
+	return &revokedTokenRepo{
+		tokens: map[string]time.Time{},
+	}
+}
+
+func (r *revokedTokenRepo) Revoke(ctx context.Context, tokenId string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// tokens past their expiry are rejected anyway, so there is no need to remember them
+	now := time.Now()
+	for id, exp := range r.tokens {
+		if exp.Before(now) {
+			delete(r.tokens, id)
+		}
+	}
+
+	if _, ok := r.tokens[tokenId]; ok {
+		return false, nil
+	}
+
+	r.tokens[tokenId] = expiresAt
+	return true, nil
+}
+
+func (r *revokedTokenRepo) IsRevoked(ctx context.Context, tokenId string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.tokens[tokenId]
+	return ok, nil
+}
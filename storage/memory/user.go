@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"book-api-gateway/storage"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type userRepo struct {
+	byId    map[string]*storage.User
+	byLogin map[string]*storage.User
+	byPhone map[string]*storage.User
+	byEmail map[string]*storage.User
+}
+
+// newUserRepo loads a JSON array of storage.User from path; an empty path yields no users
+func newUserRepo(path string) (*userRepo, error) {
+	r := &userRepo{
+		byId:    map[string]*storage.User{},
+		byLogin: map[string]*storage.User{},
+		byPhone: map[string]*storage.User{},
+		byEmail: map[string]*storage.User{},
+	}
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading users file: %w", err)
+	}
+
+	var users []*storage.User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("parsing users file %s: %w", path, err)
+	}
+
+	for _, u := range users {
+		r.byId[u.Id] = u
+		r.byLogin[u.Login] = u
+		if u.Phone != "" {
+			r.byPhone[u.Phone] = u
+		}
+		if u.Email != "" {
+			r.byEmail[strings.ToLower(u.Email)] = u
+		}
+	}
+
+	return r, nil
+}
+
+func (r *userRepo) GetById(ctx context.Context, id string) (*storage.User, error) {
+	u, ok := r.byId[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return u, nil
+}
+
+func (r *userRepo) GetByLogin(ctx context.Context, login string) (*storage.User, error) {
+	u, ok := r.byLogin[login]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return u, nil
+}
+
+func (r *userRepo) GetByPhone(ctx context.Context, phone string) (*storage.User, error) {
+	u, ok := r.byPhone[phone]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return u, nil
+}
+
+func (r *userRepo) GetByEmail(ctx context.Context, email string) (*storage.User, error) {
+	u, ok := r.byEmail[strings.ToLower(email)]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return u, nil
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by repos when the requested record does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrCooldown is returned when a new one-time code is asked for before the resend cooldown of the pending one has passed
+var ErrCooldown = errors.New("resend cooldown has not passed")
+
+// StorageI ...
+type StorageI interface {
+	User() UserRepoI
+	RevokedToken() RevokedTokenRepoI
+	OTP() OTPRepoI
+	ApiKey() ApiKeyRepoI
+}
+
+// User is a gateway account able to log in
+type User struct {
+	Id           string `json:"id"`
+	Login        string `json:"login"`
+	PasswordHash string `json:"password_hash"`
+	UserType     string `json:"user_type"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email"`
+}
+
+// UserRepoI ...
+type UserRepoI interface {
+	GetById(ctx context.Context, id string) (*User, error)
+	GetByLogin(ctx context.Context, login string) (*User, error)
+	GetByPhone(ctx context.Context, phone string) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+}
+
+// RevokedTokenRepoI keeps ids of revoked tokens until they would have expired anyway
+type RevokedTokenRepoI interface {
+	// Revoke atomically revokes tokenId unless it already is, reporting whether this call revoked it
+	Revoke(ctx context.Context, tokenId string, expiresAt time.Time) (bool, error)
+	IsRevoked(ctx context.Context, tokenId string) (bool, error)
+}
+
+// OTP is a one-time code issued to a phone number or email address
+type OTP struct {
+	Recipient string
+	Code      string
+	SentAt    time.Time
+	ExpiresAt time.Time
+	Attempts  int
+}
+
+// OTPRepoI keeps at most one pending code per recipient
+type OTPRepoI interface {
+	// Save replaces the pending code of the recipient, failing with ErrCooldown while it was sent less than cooldown ago
+	Save(ctx context.Context, otp *OTP, cooldown time.Duration) error
+	Get(ctx context.Context, recipient string) (*OTP, error)
+	// IncrementAttempts atomically counts a verification attempt and returns the pending code as it stands after it
+	IncrementAttempts(ctx context.Context, recipient string) (*OTP, error)
+	Delete(ctx context.Context, recipient string) error
+}
+
+// ApiKeyScope allows an api key to call Path (a route pattern, "*" suffix matches a prefix) with Method ("*" for any)
+type ApiKeyScope struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// ApiKey is a service credential; only the bcrypt hash of its secret is stored.
+// A key acts with the role of the user who created it, further narrowed by its scopes.
+type ApiKey struct {
+	Id         string        `json:"id"`
+	Name       string        `json:"name"`
+	SecretHash string        `json:"secret_hash"`
+	Scopes     []ApiKeyScope `json:"scopes"`
+	Role       string        `json:"role"`
+	CreatedBy  string        `json:"created_by"`
+	ExpiresAt  *time.Time    `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// ApiKeyRepoI ...
+type ApiKeyRepoI interface {
+	Create(ctx context.Context, key *ApiKey) error
+	GetById(ctx context.Context, id string) (*ApiKey, error)
+	GetAll(ctx context.Context) ([]*ApiKey, error)
+	Delete(ctx context.Context, id string) error
+}
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+// Job is a long-running import or export executed in the background
+type Job struct {
+	Id     string `json:"id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	// Owner is the user id, or "api_key:<id>", of whoever submitted the job
+	Owner string `json:"owner"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+	Error string `json:"error,omitempty"`
+	// Summary is a JSON document describing the outcome, e.g. an import report
+	Summary []byte `json:"summary,omitempty"`
+	// ResultFile is the path of the downloadable result, if the job produces one
+	ResultFile        string     `json:"result_file,omitempty"`
+	ResultName        string     `json:"result_name,omitempty"`
+	ResultContentType string     `json:"result_content_type,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	StartedAt         *time.Time `json:"started_at,omitempty"`
+	FinishedAt        *time.Time `json:"finished_at,omitempty"`
+}
+
+// Finished reports whether the job reached a final status
+func (j *Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// JobRepoI is not part of StorageI: jobs may have to survive restarts, so their store is chosen on its own
+type JobRepoI interface {
+	Create(ctx context.Context, job *Job) error
+	GetById(ctx context.Context, id string) (*Job, error)
+	GetAll(ctx context.Context) ([]*Job, error)
+	Update(ctx context.Context, job *Job) error
+	Delete(ctx context.Context, id string) error
+}